@@ -0,0 +1,1169 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: ipverifier/v1/ip_verifier.proto
+
+package ipverifierv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Policy holds inline policy rules. The fields and their semantics match the
+// REST API's policy fields.
+type Policy struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AllowedCountries []string               `protobuf:"bytes,1,rep,name=allowed_countries,json=allowedCountries,proto3" json:"allowed_countries,omitempty"`
+	DeniedCountries  []string               `protobuf:"bytes,2,rep,name=denied_countries,json=deniedCountries,proto3" json:"denied_countries,omitempty"`
+	AllowedAsns      []uint32               `protobuf:"varint,3,rep,packed,name=allowed_asns,json=allowedAsns,proto3" json:"allowed_asns,omitempty"`
+	DeniedAsns       []uint32               `protobuf:"varint,4,rep,packed,name=denied_asns,json=deniedAsns,proto3" json:"denied_asns,omitempty"`
+	AllowedCidrs     []string               `protobuf:"bytes,5,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"`
+	DeniedCidrs      []string               `protobuf:"bytes,6,rep,name=denied_cidrs,json=deniedCidrs,proto3" json:"denied_cidrs,omitempty"`
+	// vpn, tor, hosting or public_proxy
+	DeniedAnonymity []string `protobuf:"bytes,7,rep,name=denied_anonymity,json=deniedAnonymity,proto3" json:"denied_anonymity,omitempty"`
+	// allow, deny or error
+	UnknownAction string `protobuf:"bytes,8,opt,name=unknown_action,json=unknownAction,proto3" json:"unknown_action,omitempty"`
+	AllowInternal bool   `protobuf:"varint,9,opt,name=allow_internal,json=allowInternal,proto3" json:"allow_internal,omitempty"`
+	// physical, registered, any or all
+	MatchMode     string `protobuf:"bytes,10,opt,name=match_mode,json=matchMode,proto3" json:"match_mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Policy) Reset() {
+	*x = Policy{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Policy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Policy) ProtoMessage() {}
+
+func (x *Policy) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Policy.ProtoReflect.Descriptor instead.
+func (*Policy) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Policy) GetAllowedCountries() []string {
+	if x != nil {
+		return x.AllowedCountries
+	}
+	return nil
+}
+
+func (x *Policy) GetDeniedCountries() []string {
+	if x != nil {
+		return x.DeniedCountries
+	}
+	return nil
+}
+
+func (x *Policy) GetAllowedAsns() []uint32 {
+	if x != nil {
+		return x.AllowedAsns
+	}
+	return nil
+}
+
+func (x *Policy) GetDeniedAsns() []uint32 {
+	if x != nil {
+		return x.DeniedAsns
+	}
+	return nil
+}
+
+func (x *Policy) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
+func (x *Policy) GetDeniedCidrs() []string {
+	if x != nil {
+		return x.DeniedCidrs
+	}
+	return nil
+}
+
+func (x *Policy) GetDeniedAnonymity() []string {
+	if x != nil {
+		return x.DeniedAnonymity
+	}
+	return nil
+}
+
+func (x *Policy) GetUnknownAction() string {
+	if x != nil {
+		return x.UnknownAction
+	}
+	return ""
+}
+
+func (x *Policy) GetAllowInternal() bool {
+	if x != nil {
+		return x.AllowInternal
+	}
+	return false
+}
+
+func (x *Policy) GetMatchMode() string {
+	if x != nil {
+		return x.MatchMode
+	}
+	return ""
+}
+
+type VerifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ip    string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// Exactly one of policy_id and policy must be set.
+	PolicyId      string  `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Policy        *Policy `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VerifyRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetPolicy() *Policy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+type Coordinates struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Latitude         float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude        float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	AccuracyRadiusKm uint32                 `protobuf:"varint,3,opt,name=accuracy_radius_km,json=accuracyRadiusKm,proto3" json:"accuracy_radius_km,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Coordinates) Reset() {
+	*x = Coordinates{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coordinates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coordinates) ProtoMessage() {}
+
+func (x *Coordinates) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coordinates.ProtoReflect.Descriptor instead.
+func (*Coordinates) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Coordinates) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Coordinates) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Coordinates) GetAccuracyRadiusKm() uint32 {
+	if x != nil {
+		return x.AccuracyRadiusKm
+	}
+	return 0
+}
+
+type MatchedRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Entry         string                 `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchedRule) Reset() {
+	*x = MatchedRule{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchedRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchedRule) ProtoMessage() {}
+
+func (x *MatchedRule) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchedRule.ProtoReflect.Descriptor instead.
+func (*MatchedRule) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MatchedRule) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *MatchedRule) GetEntry() string {
+	if x != nil {
+		return x.Entry
+	}
+	return ""
+}
+
+type VerifyResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Ip                 string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Country            string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Continent          string                 `protobuf:"bytes,3,opt,name=continent,proto3" json:"continent,omitempty"`
+	RegisteredCountry  string                 `protobuf:"bytes,4,opt,name=registered_country,json=registeredCountry,proto3" json:"registered_country,omitempty"`
+	RepresentedCountry string                 `protobuf:"bytes,5,opt,name=represented_country,json=representedCountry,proto3" json:"represented_country,omitempty"`
+	Subdivisions       []string               `protobuf:"bytes,6,rep,name=subdivisions,proto3" json:"subdivisions,omitempty"`
+	City               string                 `protobuf:"bytes,7,opt,name=city,proto3" json:"city,omitempty"`
+	PostalCode         string                 `protobuf:"bytes,8,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Coordinates        *Coordinates           `protobuf:"bytes,9,opt,name=coordinates,proto3" json:"coordinates,omitempty"`
+	Asn                uint32                 `protobuf:"varint,10,opt,name=asn,proto3" json:"asn,omitempty"`
+	AsOrg              string                 `protobuf:"bytes,11,opt,name=as_org,json=asOrg,proto3" json:"as_org,omitempty"`
+	IsVpn              bool                   `protobuf:"varint,12,opt,name=is_vpn,json=isVpn,proto3" json:"is_vpn,omitempty"`
+	IsTor              bool                   `protobuf:"varint,13,opt,name=is_tor,json=isTor,proto3" json:"is_tor,omitempty"`
+	IsHosting          bool                   `protobuf:"varint,14,opt,name=is_hosting,json=isHosting,proto3" json:"is_hosting,omitempty"`
+	IsPublicProxy      bool                   `protobuf:"varint,15,opt,name=is_public_proxy,json=isPublicProxy,proto3" json:"is_public_proxy,omitempty"`
+	Allowed            bool                   `protobuf:"varint,16,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	Reason             string                 `protobuf:"bytes,17,opt,name=reason,proto3" json:"reason,omitempty"`
+	RangeType          string                 `protobuf:"bytes,18,opt,name=range_type,json=rangeType,proto3" json:"range_type,omitempty"`
+	MatchedGroup       string                 `protobuf:"bytes,19,opt,name=matched_group,json=matchedGroup,proto3" json:"matched_group,omitempty"`
+	MatchedRule        *MatchedRule           `protobuf:"bytes,20,opt,name=matched_rule,json=matchedRule,proto3" json:"matched_rule,omitempty"`
+	DatabaseBuild      *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=database_build,json=databaseBuild,proto3" json:"database_build,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyResponse) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetContinent() string {
+	if x != nil {
+		return x.Continent
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetRegisteredCountry() string {
+	if x != nil {
+		return x.RegisteredCountry
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetRepresentedCountry() string {
+	if x != nil {
+		return x.RepresentedCountry
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetSubdivisions() []string {
+	if x != nil {
+		return x.Subdivisions
+	}
+	return nil
+}
+
+func (x *VerifyResponse) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetCoordinates() *Coordinates {
+	if x != nil {
+		return x.Coordinates
+	}
+	return nil
+}
+
+func (x *VerifyResponse) GetAsn() uint32 {
+	if x != nil {
+		return x.Asn
+	}
+	return 0
+}
+
+func (x *VerifyResponse) GetAsOrg() string {
+	if x != nil {
+		return x.AsOrg
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetIsVpn() bool {
+	if x != nil {
+		return x.IsVpn
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetIsTor() bool {
+	if x != nil {
+		return x.IsTor
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetIsHosting() bool {
+	if x != nil {
+		return x.IsHosting
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetIsPublicProxy() bool {
+	if x != nil {
+		return x.IsPublicProxy
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetRangeType() string {
+	if x != nil {
+		return x.RangeType
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetMatchedGroup() string {
+	if x != nil {
+		return x.MatchedGroup
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetMatchedRule() *MatchedRule {
+	if x != nil {
+		return x.MatchedRule
+	}
+	return nil
+}
+
+func (x *VerifyResponse) GetDatabaseBuild() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DatabaseBuild
+	}
+	return nil
+}
+
+type BatchVerifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ips   []string               `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"`
+	// Exactly one of policy_id and policy must be set.
+	PolicyId      string  `protobuf:"bytes,2,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Policy        *Policy `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchVerifyRequest) Reset() {
+	*x = BatchVerifyRequest{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchVerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchVerifyRequest) ProtoMessage() {}
+
+func (x *BatchVerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchVerifyRequest.ProtoReflect.Descriptor instead.
+func (*BatchVerifyRequest) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchVerifyRequest) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+func (x *BatchVerifyRequest) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *BatchVerifyRequest) GetPolicy() *Policy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+type BatchVerifyItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ip    string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	// Set when the IP was verified.
+	Result *VerifyResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	// Set when the IP could not be verified.
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchVerifyItem) Reset() {
+	*x = BatchVerifyItem{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchVerifyItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchVerifyItem) ProtoMessage() {}
+
+func (x *BatchVerifyItem) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchVerifyItem.ProtoReflect.Descriptor instead.
+func (*BatchVerifyItem) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchVerifyItem) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *BatchVerifyItem) GetResult() *VerifyResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchVerifyItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchVerifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchVerifyItem     `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchVerifyResponse) Reset() {
+	*x = BatchVerifyResponse{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchVerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchVerifyResponse) ProtoMessage() {}
+
+func (x *BatchVerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchVerifyResponse.ProtoReflect.Descriptor instead.
+func (*BatchVerifyResponse) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchVerifyResponse) GetResults() []*BatchVerifyItem {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type LookupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupRequest) Reset() {
+	*x = LookupRequest{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupRequest) ProtoMessage() {}
+
+func (x *LookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupRequest.ProtoReflect.Descriptor instead.
+func (*LookupRequest) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LookupRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+type LookupResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Ip                 string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Country            string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Continent          string                 `protobuf:"bytes,3,opt,name=continent,proto3" json:"continent,omitempty"`
+	RegisteredCountry  string                 `protobuf:"bytes,4,opt,name=registered_country,json=registeredCountry,proto3" json:"registered_country,omitempty"`
+	RepresentedCountry string                 `protobuf:"bytes,5,opt,name=represented_country,json=representedCountry,proto3" json:"represented_country,omitempty"`
+	Subdivisions       []string               `protobuf:"bytes,6,rep,name=subdivisions,proto3" json:"subdivisions,omitempty"`
+	City               string                 `protobuf:"bytes,7,opt,name=city,proto3" json:"city,omitempty"`
+	PostalCode         string                 `protobuf:"bytes,8,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Coordinates        *Coordinates           `protobuf:"bytes,9,opt,name=coordinates,proto3" json:"coordinates,omitempty"`
+	Asn                uint32                 `protobuf:"varint,10,opt,name=asn,proto3" json:"asn,omitempty"`
+	AsOrg              string                 `protobuf:"bytes,11,opt,name=as_org,json=asOrg,proto3" json:"as_org,omitempty"`
+	IsAnonymous        bool                   `protobuf:"varint,12,opt,name=is_anonymous,json=isAnonymous,proto3" json:"is_anonymous,omitempty"`
+	IsVpn              bool                   `protobuf:"varint,13,opt,name=is_vpn,json=isVpn,proto3" json:"is_vpn,omitempty"`
+	IsTor              bool                   `protobuf:"varint,14,opt,name=is_tor,json=isTor,proto3" json:"is_tor,omitempty"`
+	IsHosting          bool                   `protobuf:"varint,15,opt,name=is_hosting,json=isHosting,proto3" json:"is_hosting,omitempty"`
+	IsPublicProxy      bool                   `protobuf:"varint,16,opt,name=is_public_proxy,json=isPublicProxy,proto3" json:"is_public_proxy,omitempty"`
+	RangeType          string                 `protobuf:"bytes,17,opt,name=range_type,json=rangeType,proto3" json:"range_type,omitempty"`
+	DatabaseBuild      *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=database_build,json=databaseBuild,proto3" json:"database_build,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *LookupResponse) Reset() {
+	*x = LookupResponse{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func (x *LookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResponse.ProtoReflect.Descriptor instead.
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LookupResponse) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetContinent() string {
+	if x != nil {
+		return x.Continent
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetRegisteredCountry() string {
+	if x != nil {
+		return x.RegisteredCountry
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetRepresentedCountry() string {
+	if x != nil {
+		return x.RepresentedCountry
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetSubdivisions() []string {
+	if x != nil {
+		return x.Subdivisions
+	}
+	return nil
+}
+
+func (x *LookupResponse) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetCoordinates() *Coordinates {
+	if x != nil {
+		return x.Coordinates
+	}
+	return nil
+}
+
+func (x *LookupResponse) GetAsn() uint32 {
+	if x != nil {
+		return x.Asn
+	}
+	return 0
+}
+
+func (x *LookupResponse) GetAsOrg() string {
+	if x != nil {
+		return x.AsOrg
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetIsAnonymous() bool {
+	if x != nil {
+		return x.IsAnonymous
+	}
+	return false
+}
+
+func (x *LookupResponse) GetIsVpn() bool {
+	if x != nil {
+		return x.IsVpn
+	}
+	return false
+}
+
+func (x *LookupResponse) GetIsTor() bool {
+	if x != nil {
+		return x.IsTor
+	}
+	return false
+}
+
+func (x *LookupResponse) GetIsHosting() bool {
+	if x != nil {
+		return x.IsHosting
+	}
+	return false
+}
+
+func (x *LookupResponse) GetIsPublicProxy() bool {
+	if x != nil {
+		return x.IsPublicProxy
+	}
+	return false
+}
+
+func (x *LookupResponse) GetRangeType() string {
+	if x != nil {
+		return x.RangeType
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetDatabaseBuild() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DatabaseBuild
+	}
+	return nil
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{10}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ipverifier_v1_ip_verifier_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_ipverifier_v1_ip_verifier_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+var File_ipverifier_v1_ip_verifier_proto protoreflect.FileDescriptor
+
+const file_ipverifier_v1_ip_verifier_proto_rawDesc = "" +
+	"\n" +
+	"\x1fipverifier/v1/ip_verifier.proto\x12\ripverifier.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x84\x03\n" +
+	"\x06Policy\x12+\n" +
+	"\x11allowed_countries\x18\x01 \x03(\tR\x10allowedCountries\x12)\n" +
+	"\x10denied_countries\x18\x02 \x03(\tR\x0fdeniedCountries\x12!\n" +
+	"\fallowed_asns\x18\x03 \x03(\rR\vallowedAsns\x12\x1f\n" +
+	"\vdenied_asns\x18\x04 \x03(\rR\n" +
+	"deniedAsns\x12#\n" +
+	"\rallowed_cidrs\x18\x05 \x03(\tR\fallowedCidrs\x12!\n" +
+	"\fdenied_cidrs\x18\x06 \x03(\tR\vdeniedCidrs\x12)\n" +
+	"\x10denied_anonymity\x18\a \x03(\tR\x0fdeniedAnonymity\x12%\n" +
+	"\x0eunknown_action\x18\b \x01(\tR\runknownAction\x12%\n" +
+	"\x0eallow_internal\x18\t \x01(\bR\rallowInternal\x12\x1d\n" +
+	"\n" +
+	"match_mode\x18\n" +
+	" \x01(\tR\tmatchMode\"k\n" +
+	"\rVerifyRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12-\n" +
+	"\x06policy\x18\x03 \x01(\v2\x15.ipverifier.v1.PolicyR\x06policy\"u\n" +
+	"\vCoordinates\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12,\n" +
+	"\x12accuracy_radius_km\x18\x03 \x01(\rR\x10accuracyRadiusKm\"7\n" +
+	"\vMatchedRule\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x14\n" +
+	"\x05entry\x18\x02 \x01(\tR\x05entry\"\xe5\x05\n" +
+	"\x0eVerifyResponse\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12\x1c\n" +
+	"\tcontinent\x18\x03 \x01(\tR\tcontinent\x12-\n" +
+	"\x12registered_country\x18\x04 \x01(\tR\x11registeredCountry\x12/\n" +
+	"\x13represented_country\x18\x05 \x01(\tR\x12representedCountry\x12\"\n" +
+	"\fsubdivisions\x18\x06 \x03(\tR\fsubdivisions\x12\x12\n" +
+	"\x04city\x18\a \x01(\tR\x04city\x12\x1f\n" +
+	"\vpostal_code\x18\b \x01(\tR\n" +
+	"postalCode\x12<\n" +
+	"\vcoordinates\x18\t \x01(\v2\x1a.ipverifier.v1.CoordinatesR\vcoordinates\x12\x10\n" +
+	"\x03asn\x18\n" +
+	" \x01(\rR\x03asn\x12\x15\n" +
+	"\x06as_org\x18\v \x01(\tR\x05asOrg\x12\x15\n" +
+	"\x06is_vpn\x18\f \x01(\bR\x05isVpn\x12\x15\n" +
+	"\x06is_tor\x18\r \x01(\bR\x05isTor\x12\x1d\n" +
+	"\n" +
+	"is_hosting\x18\x0e \x01(\bR\tisHosting\x12&\n" +
+	"\x0fis_public_proxy\x18\x0f \x01(\bR\risPublicProxy\x12\x18\n" +
+	"\aallowed\x18\x10 \x01(\bR\aallowed\x12\x16\n" +
+	"\x06reason\x18\x11 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"range_type\x18\x12 \x01(\tR\trangeType\x12#\n" +
+	"\rmatched_group\x18\x13 \x01(\tR\fmatchedGroup\x12=\n" +
+	"\fmatched_rule\x18\x14 \x01(\v2\x1a.ipverifier.v1.MatchedRuleR\vmatchedRule\x12A\n" +
+	"\x0edatabase_build\x18\x15 \x01(\v2\x1a.google.protobuf.TimestampR\rdatabaseBuild\"r\n" +
+	"\x12BatchVerifyRequest\x12\x10\n" +
+	"\x03ips\x18\x01 \x03(\tR\x03ips\x12\x1b\n" +
+	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12-\n" +
+	"\x06policy\x18\x03 \x01(\v2\x15.ipverifier.v1.PolicyR\x06policy\"n\n" +
+	"\x0fBatchVerifyItem\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x125\n" +
+	"\x06result\x18\x02 \x01(\v2\x1d.ipverifier.v1.VerifyResponseR\x06result\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"O\n" +
+	"\x13BatchVerifyResponse\x128\n" +
+	"\aresults\x18\x01 \x03(\v2\x1e.ipverifier.v1.BatchVerifyItemR\aresults\"\x1f\n" +
+	"\rLookupRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\"\xf2\x04\n" +
+	"\x0eLookupResponse\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12\x1c\n" +
+	"\tcontinent\x18\x03 \x01(\tR\tcontinent\x12-\n" +
+	"\x12registered_country\x18\x04 \x01(\tR\x11registeredCountry\x12/\n" +
+	"\x13represented_country\x18\x05 \x01(\tR\x12representedCountry\x12\"\n" +
+	"\fsubdivisions\x18\x06 \x03(\tR\fsubdivisions\x12\x12\n" +
+	"\x04city\x18\a \x01(\tR\x04city\x12\x1f\n" +
+	"\vpostal_code\x18\b \x01(\tR\n" +
+	"postalCode\x12<\n" +
+	"\vcoordinates\x18\t \x01(\v2\x1a.ipverifier.v1.CoordinatesR\vcoordinates\x12\x10\n" +
+	"\x03asn\x18\n" +
+	" \x01(\rR\x03asn\x12\x15\n" +
+	"\x06as_org\x18\v \x01(\tR\x05asOrg\x12!\n" +
+	"\fis_anonymous\x18\f \x01(\bR\visAnonymous\x12\x15\n" +
+	"\x06is_vpn\x18\r \x01(\bR\x05isVpn\x12\x15\n" +
+	"\x06is_tor\x18\x0e \x01(\bR\x05isTor\x12\x1d\n" +
+	"\n" +
+	"is_hosting\x18\x0f \x01(\bR\tisHosting\x12&\n" +
+	"\x0fis_public_proxy\x18\x10 \x01(\bR\risPublicProxy\x12\x1d\n" +
+	"\n" +
+	"range_type\x18\x11 \x01(\tR\trangeType\x12A\n" +
+	"\x0edatabase_build\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\rdatabaseBuild\"\x0f\n" +
+	"\rHealthRequest\"(\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status2\xbe\x02\n" +
+	"\x11IPVerifierService\x12E\n" +
+	"\x06Verify\x12\x1c.ipverifier.v1.VerifyRequest\x1a\x1d.ipverifier.v1.VerifyResponse\x12T\n" +
+	"\vBatchVerify\x12!.ipverifier.v1.BatchVerifyRequest\x1a\".ipverifier.v1.BatchVerifyResponse\x12E\n" +
+	"\x06Lookup\x12\x1c.ipverifier.v1.LookupRequest\x1a\x1d.ipverifier.v1.LookupResponse\x12E\n" +
+	"\x06Health\x12\x1c.ipverifier.v1.HealthRequest\x1a\x1d.ipverifier.v1.HealthResponseBEZCgithub.com/KWilliams-dev/ip-verifier/api/ipverifier/v1;ipverifierv1b\x06proto3"
+
+var (
+	file_ipverifier_v1_ip_verifier_proto_rawDescOnce sync.Once
+	file_ipverifier_v1_ip_verifier_proto_rawDescData []byte
+)
+
+func file_ipverifier_v1_ip_verifier_proto_rawDescGZIP() []byte {
+	file_ipverifier_v1_ip_verifier_proto_rawDescOnce.Do(func() {
+		file_ipverifier_v1_ip_verifier_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ipverifier_v1_ip_verifier_proto_rawDesc), len(file_ipverifier_v1_ip_verifier_proto_rawDesc)))
+	})
+	return file_ipverifier_v1_ip_verifier_proto_rawDescData
+}
+
+var file_ipverifier_v1_ip_verifier_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_ipverifier_v1_ip_verifier_proto_goTypes = []any{
+	(*Policy)(nil),                // 0: ipverifier.v1.Policy
+	(*VerifyRequest)(nil),         // 1: ipverifier.v1.VerifyRequest
+	(*Coordinates)(nil),           // 2: ipverifier.v1.Coordinates
+	(*MatchedRule)(nil),           // 3: ipverifier.v1.MatchedRule
+	(*VerifyResponse)(nil),        // 4: ipverifier.v1.VerifyResponse
+	(*BatchVerifyRequest)(nil),    // 5: ipverifier.v1.BatchVerifyRequest
+	(*BatchVerifyItem)(nil),       // 6: ipverifier.v1.BatchVerifyItem
+	(*BatchVerifyResponse)(nil),   // 7: ipverifier.v1.BatchVerifyResponse
+	(*LookupRequest)(nil),         // 8: ipverifier.v1.LookupRequest
+	(*LookupResponse)(nil),        // 9: ipverifier.v1.LookupResponse
+	(*HealthRequest)(nil),         // 10: ipverifier.v1.HealthRequest
+	(*HealthResponse)(nil),        // 11: ipverifier.v1.HealthResponse
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_ipverifier_v1_ip_verifier_proto_depIdxs = []int32{
+	0,  // 0: ipverifier.v1.VerifyRequest.policy:type_name -> ipverifier.v1.Policy
+	2,  // 1: ipverifier.v1.VerifyResponse.coordinates:type_name -> ipverifier.v1.Coordinates
+	3,  // 2: ipverifier.v1.VerifyResponse.matched_rule:type_name -> ipverifier.v1.MatchedRule
+	12, // 3: ipverifier.v1.VerifyResponse.database_build:type_name -> google.protobuf.Timestamp
+	0,  // 4: ipverifier.v1.BatchVerifyRequest.policy:type_name -> ipverifier.v1.Policy
+	4,  // 5: ipverifier.v1.BatchVerifyItem.result:type_name -> ipverifier.v1.VerifyResponse
+	6,  // 6: ipverifier.v1.BatchVerifyResponse.results:type_name -> ipverifier.v1.BatchVerifyItem
+	2,  // 7: ipverifier.v1.LookupResponse.coordinates:type_name -> ipverifier.v1.Coordinates
+	12, // 8: ipverifier.v1.LookupResponse.database_build:type_name -> google.protobuf.Timestamp
+	1,  // 9: ipverifier.v1.IPVerifierService.Verify:input_type -> ipverifier.v1.VerifyRequest
+	5,  // 10: ipverifier.v1.IPVerifierService.BatchVerify:input_type -> ipverifier.v1.BatchVerifyRequest
+	8,  // 11: ipverifier.v1.IPVerifierService.Lookup:input_type -> ipverifier.v1.LookupRequest
+	10, // 12: ipverifier.v1.IPVerifierService.Health:input_type -> ipverifier.v1.HealthRequest
+	4,  // 13: ipverifier.v1.IPVerifierService.Verify:output_type -> ipverifier.v1.VerifyResponse
+	7,  // 14: ipverifier.v1.IPVerifierService.BatchVerify:output_type -> ipverifier.v1.BatchVerifyResponse
+	9,  // 15: ipverifier.v1.IPVerifierService.Lookup:output_type -> ipverifier.v1.LookupResponse
+	11, // 16: ipverifier.v1.IPVerifierService.Health:output_type -> ipverifier.v1.HealthResponse
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_ipverifier_v1_ip_verifier_proto_init() }
+func file_ipverifier_v1_ip_verifier_proto_init() {
+	if File_ipverifier_v1_ip_verifier_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ipverifier_v1_ip_verifier_proto_rawDesc), len(file_ipverifier_v1_ip_verifier_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ipverifier_v1_ip_verifier_proto_goTypes,
+		DependencyIndexes: file_ipverifier_v1_ip_verifier_proto_depIdxs,
+		MessageInfos:      file_ipverifier_v1_ip_verifier_proto_msgTypes,
+	}.Build()
+	File_ipverifier_v1_ip_verifier_proto = out.File
+	file_ipverifier_v1_ip_verifier_proto_goTypes = nil
+	file_ipverifier_v1_ip_verifier_proto_depIdxs = nil
+}
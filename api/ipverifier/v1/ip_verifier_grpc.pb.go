@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: ipverifier/v1/ip_verifier.proto
+
+package ipverifierv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IPVerifierService_Verify_FullMethodName      = "/ipverifier.v1.IPVerifierService/Verify"
+	IPVerifierService_BatchVerify_FullMethodName = "/ipverifier.v1.IPVerifierService/BatchVerify"
+	IPVerifierService_Lookup_FullMethodName      = "/ipverifier.v1.IPVerifierService/Lookup"
+	IPVerifierService_Health_FullMethodName      = "/ipverifier.v1.IPVerifierService/Health"
+)
+
+// IPVerifierServiceClient is the client API for IPVerifierService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IPVerifierService mirrors the REST API: it verifies IPs against geo
+// policies and looks up the data known about an IP.
+type IPVerifierServiceClient interface {
+	// Verify checks one IP against a named or inline policy.
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// BatchVerify checks several IPs against the same policy. An invalid IP
+	// only fails its own item.
+	BatchVerify(ctx context.Context, in *BatchVerifyRequest, opts ...grpc.CallOption) (*BatchVerifyResponse, error)
+	// Lookup returns the location, ASN and anonymity data of an IP without
+	// applying a policy.
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	// Health reports whether the GeoIP databases are available. It fails with
+	// UNAVAILABLE when they are not.
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type iPVerifierServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIPVerifierServiceClient(cc grpc.ClientConnInterface) IPVerifierServiceClient {
+	return &iPVerifierServiceClient{cc}
+}
+
+func (c *iPVerifierServiceClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, IPVerifierService_Verify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPVerifierServiceClient) BatchVerify(ctx context.Context, in *BatchVerifyRequest, opts ...grpc.CallOption) (*BatchVerifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchVerifyResponse)
+	err := c.cc.Invoke(ctx, IPVerifierService_BatchVerify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPVerifierServiceClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, IPVerifierService_Lookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPVerifierServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, IPVerifierService_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IPVerifierServiceServer is the server API for IPVerifierService service.
+// All implementations must embed UnimplementedIPVerifierServiceServer
+// for forward compatibility.
+//
+// IPVerifierService mirrors the REST API: it verifies IPs against geo
+// policies and looks up the data known about an IP.
+type IPVerifierServiceServer interface {
+	// Verify checks one IP against a named or inline policy.
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	// BatchVerify checks several IPs against the same policy. An invalid IP
+	// only fails its own item.
+	BatchVerify(context.Context, *BatchVerifyRequest) (*BatchVerifyResponse, error)
+	// Lookup returns the location, ASN and anonymity data of an IP without
+	// applying a policy.
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+	// Health reports whether the GeoIP databases are available. It fails with
+	// UNAVAILABLE when they are not.
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	mustEmbedUnimplementedIPVerifierServiceServer()
+}
+
+// UnimplementedIPVerifierServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIPVerifierServiceServer struct{}
+
+func (UnimplementedIPVerifierServiceServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedIPVerifierServiceServer) BatchVerify(context.Context, *BatchVerifyRequest) (*BatchVerifyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchVerify not implemented")
+}
+func (UnimplementedIPVerifierServiceServer) Lookup(context.Context, *LookupRequest) (*LookupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedIPVerifierServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedIPVerifierServiceServer) mustEmbedUnimplementedIPVerifierServiceServer() {}
+func (UnimplementedIPVerifierServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeIPVerifierServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IPVerifierServiceServer will
+// result in compilation errors.
+type UnsafeIPVerifierServiceServer interface {
+	mustEmbedUnimplementedIPVerifierServiceServer()
+}
+
+func RegisterIPVerifierServiceServer(s grpc.ServiceRegistrar, srv IPVerifierServiceServer) {
+	// If the following call panics, it indicates UnimplementedIPVerifierServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IPVerifierService_ServiceDesc, srv)
+}
+
+func _IPVerifierService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPVerifierServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPVerifierService_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPVerifierServiceServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPVerifierService_BatchVerify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchVerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPVerifierServiceServer).BatchVerify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPVerifierService_BatchVerify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPVerifierServiceServer).BatchVerify(ctx, req.(*BatchVerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPVerifierService_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPVerifierServiceServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPVerifierService_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPVerifierServiceServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPVerifierService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPVerifierServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPVerifierService_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPVerifierServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IPVerifierService_ServiceDesc is the grpc.ServiceDesc for IPVerifierService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IPVerifierService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ipverifier.v1.IPVerifierService",
+	HandlerType: (*IPVerifierServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Verify",
+			Handler:    _IPVerifierService_Verify_Handler,
+		},
+		{
+			MethodName: "BatchVerify",
+			Handler:    _IPVerifierService_BatchVerify_Handler,
+		},
+		{
+			MethodName: "Lookup",
+			Handler:    _IPVerifierService_Lookup_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _IPVerifierService_Health_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ipverifier/v1/ip_verifier.proto",
+}
@@ -3,25 +3,48 @@ package main
 import (
 	"context"
 	"errors"
-	"ip-verifier/internal/api/handler"
-	"ip-verifier/internal/config"
-	"ip-verifier/internal/repo"
-	"ip-verifier/internal/service"
+	"fmt"
+	ipverifierv1 "github.com/KWilliams-dev/ip-verifier/api/ipverifier/v1"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/extauthz"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/grpcapi"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/handler"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/middleware"
+	"github.com/KWilliams-dev/ip-verifier/internal/config"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/metrics"
+	"github.com/KWilliams-dev/ip-verifier/internal/repo"
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	"github.com/gin-gonic/gin"
-	"github.com/oschwald/geoip2-golang"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	// Setup structured logging
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	// Setup structured logging; the level is raised or lowered once the
+	// configuration is loaded. Records logged with a request context carry
+	// the request ID.
+	logLevel := new(slog.LevelVar)
+	logger := slog.New(requestid.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logLevel,
+	})))
 	slog.SetDefault(logger)
 
 	// Load configuration
@@ -30,11 +53,25 @@ func main() {
 		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+	logLevel.Set(cfg.Log.Level)
 
 	slog.Info("Configuration loaded",
 		"port", cfg.Server.Port,
+		"grpc_port", cfg.Server.GRPCPort,
+		"ext_authz_port", cfg.Server.ExtAuthzPort,
 		"environment", cfg.Server.Environment,
+		"trusted_proxies", cfg.Server.TrustedProxies,
+		"client_ip_header", cfg.Server.ClientIPHeader,
 		"geoip_path", cfg.Database.GeoIPPath,
+		"geoip_city_path", cfg.Database.CityPath,
+		"geoip_asn_path", cfg.Database.ASNPath,
+		"geoip_anonymous_ip_path", cfg.Database.AnonymousPath,
+		"tor_exit_list_path", cfg.Database.TorExitPath,
+		"geoip_reload_interval", cfg.Database.ReloadInterval,
+		"otlp_endpoint", cfg.Tracing.Endpoint,
+		"log_level", cfg.Log.Level,
+		"access_log_sample_rate", cfg.Log.SampleRate,
+		"log_ip_anonymisation", cfg.Log.IPAnonymisation,
 	)
 
 	// Set Gin mode based on environment
@@ -43,24 +80,146 @@ func main() {
 	}
 
 	// Open GeoIP database
-	db, err := geoip2.Open(cfg.Database.GeoIPPath)
+	db, err := repo.OpenDatabase(cfg.Database.GeoIPPath)
 	if err != nil {
 		slog.Error("Failed to open GeoIP database", "error", err, "path", cfg.Database.GeoIPPath)
 		os.Exit(1)
 	}
 	defer db.Close()
-	slog.Info("GeoIP database opened successfully")
+	slog.Info("GeoIP database opened successfully",
+		"database_type", db.DatabaseType(),
+		"build_epoch", db.BuildEpoch(),
+	)
+
+	sources := []reloadable{db}
+	databases := []metrics.Database{db}
+	var repoOpts []repo.Option
+
+	// Open the optional City, ASN and Anonymous IP databases
+	optional := []struct {
+		path   string
+		option func(*repo.Database) repo.Option
+	}{
+		{cfg.Database.CityPath, repo.WithCityDatabase},
+		{cfg.Database.ASNPath, repo.WithASNDatabase},
+		{cfg.Database.AnonymousPath, repo.WithAnonymousIPDatabase},
+	}
+	for _, o := range optional {
+		if o.path == "" {
+			continue
+		}
+		optionalDB, err := repo.OpenDatabase(o.path)
+		if err != nil {
+			slog.Error("Failed to open GeoIP database", "error", err, "path", o.path)
+			os.Exit(1)
+		}
+		defer optionalDB.Close()
+		slog.Info("GeoIP database opened successfully",
+			"path", o.path,
+			"database_type", optionalDB.DatabaseType(),
+			"build_epoch", optionalDB.BuildEpoch(),
+		)
+		sources = append(sources, optionalDB)
+		databases = append(databases, optionalDB)
+		repoOpts = append(repoOpts, o.option(optionalDB))
+	}
+
+	// Load the optional Tor exit node list
+	if cfg.Database.TorExitPath != "" {
+		torList, err := repo.OpenTorExitList(cfg.Database.TorExitPath)
+		if err != nil {
+			slog.Error("Failed to load Tor exit list", "error", err, "path", cfg.Database.TorExitPath)
+			os.Exit(1)
+		}
+		slog.Info("Tor exit list loaded successfully",
+			"path", cfg.Database.TorExitPath,
+			"count", torList.Len(),
+		)
+		sources = append(sources, torList)
+		repoOpts = append(repoOpts, repo.WithTorExitList(torList))
+	}
+
+	// Pick up database updates written by the geoip-updater CronJob
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if cfg.Database.ReloadInterval > 0 {
+		for _, source := range sources {
+			go source.Watch(watchCtx, cfg.Database.ReloadInterval)
+		}
+	}
+	go reloadOnSignal(watchCtx, sources)
+
+	// Setup OpenTelemetry tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		slog.Error("Failed to setup tracing", "error", err, "endpoint", cfg.Tracing.Endpoint)
+		os.Exit(1)
+	}
+
+	// Setup Prometheus metrics
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.NewDatabaseCollector(databases...),
+	)
+	appMetrics := metrics.New(registry)
 
 	// Initialize layers
-	ipRepo := repo.NewIPVerifierRepo(db)
-	ipService := service.NewIPVerifierService(ipRepo)
+	ipRepo := repo.NewIPVerifierRepo(db, repoOpts...)
+	var compilerOpts []service.CompilerOption
+	if cfg.Database.CityPath == "" {
+		compilerOpts = append(compilerOpts, service.WithoutSubdivisions())
+	}
+	compiler := service.NewPolicyCompiler(compilerOpts...)
+	ipService := metrics.InstrumentService(service.NewIPVerifierService(ipRepo,
+		service.WithMaxBatchSize(cfg.Batch.MaxSize),
+		service.WithBatchConcurrency(cfg.Batch.Concurrency),
+		service.WithPolicyCompiler(compiler),
+	), appMetrics)
+	policyService := service.NewPolicyService(repo.NewPolicyRepo(), compiler)
+	if cfg.Policy.File != "" {
+		if err := loadPolicies(policyService, cfg.Policy.File); err != nil {
+			slog.Error("Failed to load policies", "error", err, "path", cfg.Policy.File)
+			os.Exit(1)
+		}
+	}
+	resolver, err := clientip.NewResolver(cfg.Server.TrustedProxies, clientip.Source(cfg.Server.ClientIPHeader))
+	if err != nil {
+		slog.Error("Failed to configure trusted proxies", "error", err)
+		os.Exit(1)
+	}
 	slog.Info("Application layers initialized")
 
 	// Setup router
-	router := gin.Default()
+	router := gin.New()
+	router.Use(
+		middleware.RequestID(),
+		middleware.Tracing(),
+		middleware.AccessLog(logger, resolver,
+			middleware.WithAccessLogLevel(cfg.Log.AccessLevel),
+			middleware.WithSampleRate(cfg.Log.SampleRate),
+			middleware.WithIPAnonymiser(ipAnonymiser(cfg.Log)),
+		),
+		middleware.RecordMetrics(appMetrics),
+		gin.Recovery(),
+	)
 
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})))
 	router.GET("/api/v1/health", handler.HealthCheck(ipService))
-	router.POST("/api/v1/ip-verifier", handler.VerifyIP(ipService))
+	router.POST("/api/v1/ip-verifier", handler.VerifyIP(ipService, policyService))
+	router.POST("/api/v1/ip-verifier/batch", handler.VerifyIPBatch(ipService, policyService, cfg.Batch.MaxSize))
+	router.GET("/api/v1/ip-verifier/lookup/:ip", handler.LookupIP(ipService))
+	router.POST("/api/v1/ip-verifier/self", handler.VerifyClientIP(resolver, ipService, policyService))
+	router.GET("/api/v1/forward-auth", handler.ForwardAuth(resolver, ipService, policyService))
+	router.HEAD("/api/v1/forward-auth", handler.ForwardAuth(resolver, ipService, policyService))
+
+	if !registerAdminRoutes(router, cfg.Policy.AdminToken, policyService) {
+		slog.Warn("ADMIN_TOKEN is not set, policy admin endpoints are disabled")
+	}
 
 	// Configure HTTP server
 	srv := &http.Server{
@@ -82,6 +241,31 @@ func main() {
 		}
 	}()
 
+	// Start the optional gRPC servers
+	grpcServers := make(map[string]*grpc.Server)
+
+	var grpcHealth *health.Server
+	if cfg.Server.GRPCPort != "" {
+		apiServer := grpc.NewServer()
+		ipverifierv1.RegisterIPVerifierServiceServer(apiServer, grpcapi.NewServer(ipService, policyService))
+
+		grpcHealth = health.NewServer()
+		grpcHealth.SetServingStatus(ipverifierv1.IPVerifierService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		healthpb.RegisterHealthServer(apiServer, grpcHealth)
+		reflection.Register(apiServer)
+
+		serveGRPC("gRPC API", cfg.GetGRPCAddress(), apiServer)
+		grpcServers["gRPC API"] = apiServer
+	}
+
+	if cfg.Server.ExtAuthzPort != "" {
+		authzServer := grpc.NewServer()
+		authv3.RegisterAuthorizationServer(authzServer, extauthz.NewServer(ipService, policyService))
+
+		serveGRPC("ext_authz", cfg.GetExtAuthzAddress(), authzServer)
+		grpcServers["ext_authz"] = authzServer
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -93,10 +277,140 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
-	// Attempt graceful shutdown
+	// Report NOT_SERVING to gRPC health checks while draining
+	if grpcHealth != nil {
+		grpcHealth.Shutdown()
+	}
+
+	// Attempt graceful shutdown of all servers within the same deadline
+	var wg sync.WaitGroup
+	for name, server := range grpcServers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stopGRPCServer(ctx, name, server)
+		}()
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 	} else {
 		slog.Info("Server stopped gracefully")
 	}
+	wg.Wait()
+
+	// Flush the spans of the drained requests
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+}
+
+// serveGRPC starts server on address in the background, exiting the process
+// if it cannot listen or fails
+func serveGRPC(name, address string, server *grpc.Server) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		slog.Error("Failed to listen", "server", name, "error", err, "address", address)
+		os.Exit(1)
+	}
+
+	go func() {
+		slog.Info("Starting server", "server", name, "address", address)
+		if err := server.Serve(listener); err != nil {
+			slog.Error("Server failed", "server", name, "error", err)
+			os.Exit(1)
+		}
+	}()
+}
+
+// stopGRPCServer waits for in-flight RPCs to finish, closing any that remain
+// when ctx expires
+func stopGRPCServer(ctx context.Context, name string, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		slog.Info("Server stopped gracefully", "server", name)
+	case <-ctx.Done():
+		server.Stop()
+		slog.Error("Server forced to shutdown", "server", name, "error", ctx.Err())
+	}
+}
+
+// registerAdminRoutes mounts the policy admin endpoints behind the bearer
+// token. Without a token they are not mounted at all, so a deployment that
+// forgot to set one cannot have its policies rewritten by anyone.
+func registerAdminRoutes(router gin.IRouter, token string, policyService domain.PolicyService) bool {
+	if token == "" {
+		return false
+	}
+
+	admin := router.Group("/api/v1/admin", middleware.RequireBearerToken(token))
+	admin.GET("/policies", handler.ListPolicies(policyService))
+	admin.POST("/policies", handler.CreatePolicy(policyService))
+	admin.GET("/policies/:id", handler.GetPolicy(policyService))
+	admin.PUT("/policies/:id", handler.UpdatePolicy(policyService))
+	admin.DELETE("/policies/:id", handler.DeletePolicy(policyService))
+	return true
+}
+
+// ipAnonymiser returns the access log anonymiser of the configured mode, or
+// nil when client IPs are logged as they are
+func ipAnonymiser(cfg config.LogConfig) middleware.IPAnonymiser {
+	switch cfg.IPAnonymisation {
+	case config.IPAnonymisationTruncate:
+		return middleware.TruncateIP
+	case config.IPAnonymisationHash:
+		return middleware.HashIP([]byte(cfg.IPHashKey))
+	default:
+		return nil
+	}
+}
+
+// loadPolicies validates and stores the named policies from the policy file
+func loadPolicies(policyService domain.PolicyService, path string) error {
+	policies, err := repo.LoadPolicyFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if _, err := policyService.CreatePolicy(context.Background(), policy); err != nil {
+			return fmt.Errorf("policy %q: %w", policy.ID, err)
+		}
+	}
+	slog.Info("Policies loaded", "path", path, "count", len(policies))
+	return nil
+}
+
+// reloadable is a data file that can be watched and reloaded at runtime
+type reloadable interface {
+	Path() string
+	Reload() error
+	Watch(ctx context.Context, interval time.Duration)
+}
+
+// reloadOnSignal reloads the data files whenever the process receives SIGHUP
+func reloadOnSignal(ctx context.Context, sources []reloadable) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			for _, source := range sources {
+				slog.Info("SIGHUP received, reloading data file", "path", source.Path())
+				if err := source.Reload(); err != nil {
+					slog.Error("Failed to reload data file", "error", err, "path", source.Path())
+				}
+			}
+		}
+	}
 }
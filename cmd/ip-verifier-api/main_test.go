@@ -1,6 +1,8 @@
 package main
 
 import (
+	"github.com/KWilliams-dev/ip-verifier/internal/repo"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -26,3 +28,39 @@ func TestHealthEndpoint(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.JSONEq(t, `{"status":"healthy"}`, w.Body.String())
 }
+
+func TestRegisterAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		token          string
+		method         string
+		path           string
+		header         string
+		expectedStatus int
+	}{
+		{"no token configured, list", "", "GET", "/api/v1/admin/policies", "", http.StatusNotFound},
+		{"no token configured, create", "", "POST", "/api/v1/admin/policies", "Bearer ", http.StatusNotFound},
+		{"no token configured, delete", "", "DELETE", "/api/v1/admin/policies/eu-only", "", http.StatusNotFound},
+		{"missing header", "secret", "DELETE", "/api/v1/admin/policies/eu-only", "", http.StatusUnauthorized},
+		{"valid token", "secret", "GET", "/api/v1/admin/policies", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			registered := registerAdminRoutes(router, tt.token, service.NewPolicyService(repo.NewPolicyRepo(), service.NewPolicyCompiler()))
+			assert.Equal(t, tt.token != "", registered)
+
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
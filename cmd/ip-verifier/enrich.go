@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"io"
+	"os"
+	"runtime"
+)
+
+// enrichQueueDepth is how many entries per worker may be read ahead of the
+// writer, which bounds memory use on large logs
+const enrichQueueDepth = 64
+
+// enrichJob is an entry on its way through the worker pool. done is closed
+// once out holds the rendered entry.
+type enrichJob struct {
+	entry  logEntry
+	out    []byte
+	err    error
+	failed bool
+	done   chan struct{}
+}
+
+// enricher computes the values appended to each entry
+type enricher struct {
+	ipService domain.IPVerifierService
+	policy    *domain.Policy // nil when only the location is added
+}
+
+// columns returns the names of the added fields
+func (e *enricher) columns() []string {
+	if e.policy == nil {
+		return enrichColumns[:2]
+	}
+	return enrichColumns
+}
+
+// values returns the country, continent and, with a policy, the decision
+// for ip. Values that are unknown are nil.
+func (e *enricher) values(ctx context.Context, ip string) ([]*string, error) {
+	var country, continent string
+	values := make([]*string, len(e.columns()))
+
+	if e.policy == nil {
+		result, err := e.ipService.LookupIP(ctx, ip)
+		if err != nil {
+			return values, err
+		}
+		if result.Location != nil {
+			country, continent = result.Location.Country, result.Location.Continent
+		}
+	} else {
+		result, err := e.ipService.VerifyIP(ctx, ip, *e.policy)
+		if err != nil {
+			return values, err
+		}
+		country, continent = result.Country, result.Continent
+		decision := "deny"
+		if result.Allowed {
+			decision = "allow"
+		}
+		values[2] = &decision
+	}
+
+	if country != "" {
+		values[0] = &country
+	}
+	if continent != "" {
+		values[1] = &continent
+	}
+	return values, nil
+}
+
+func (c *cli) enrich(args []string) int {
+	fs := c.newFlagSet("enrich", "enrich [flags] [log file]")
+	var databases databaseFlags
+	databases.register(fs)
+	var policyOpts policyFlags
+	policyOpts.register(fs)
+	format := fs.String("log-format", logCombined, "`format` of the log: combined, jsonl or csv")
+	field := fs.String("field", "", "client IP `field`: a field number for combined logs (default 1), a dotted key for jsonl or a column for csv (default remote_addr)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of `lookups` run in parallel")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+	if len(files) > 1 {
+		return c.fail(errors.New("enrich reads a single log file"))
+	}
+	if *workers < 1 {
+		return c.fail(errors.New("--workers must be at least 1"))
+	}
+
+	ctx := context.Background()
+	compiler := databases.compiler()
+	e := &enricher{}
+	if !policyOpts.empty() {
+		policy, err := policyOpts.policy(ctx, compiler)
+		if err != nil {
+			return c.fail(err)
+		}
+		e.policy = &policy
+	}
+
+	in := c.stdin
+	if len(files) == 1 && files[0] != "-" {
+		f, err := os.Open(files[0])
+		if err != nil {
+			return c.fail(err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	ipService, closer, err := databases.open(service.WithPolicyCompiler(compiler))
+	if err != nil {
+		return c.fail(err)
+	}
+	defer closer.Close()
+	e.ipService = ipService
+
+	out := bufio.NewWriter(c.stdout)
+	log, err := newLogFormat(*format, *field, e.columns(), in, out)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	failed, err := enrichLog(ctx, log, e, out, *workers)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+	if failed > 0 {
+		fmt.Fprintf(c.stderr, "ip-verifier: %d entries could not be enriched\n", failed)
+	}
+	return exitOK
+}
+
+// enrichLog streams the entries of log through a pool of workers and writes
+// them to out in their original order. It returns the number of entries
+// whose client IP could not be found or checked; those are written with
+// empty values.
+func enrichLog(ctx context.Context, log logFormat, e *enricher, out io.Writer, workers int) (int, error) {
+	jobs := make(chan *enrichJob, workers)
+	ordered := make(chan *enrichJob, workers*enrichQueueDepth)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	var readErr error
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		for {
+			entry, err := log.read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+
+			job := &enrichJob{entry: entry, done: make(chan struct{})}
+			select {
+			case ordered <- job:
+			case <-stop:
+				return
+			}
+			jobs <- job
+		}
+	}()
+
+	for range workers {
+		go func() {
+			for job := range jobs {
+				var values []*string
+				ip, err := log.clientIP(job.entry)
+				if err == nil {
+					values, err = e.values(ctx, ip)
+				}
+				if err != nil {
+					job.failed = true
+					values = make([]*string, len(e.columns()))
+				}
+				job.out, job.err = log.render(job.entry, values)
+				close(job.done)
+			}
+		}()
+	}
+
+	failed := 0
+	for job := range ordered {
+		<-job.done
+		if job.err != nil {
+			return failed, job.err
+		}
+		if job.failed {
+			failed++
+		}
+		if _, err := out.Write(job.out); err != nil {
+			return failed, err
+		}
+	}
+	return failed, readErr
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubService answers lookups from a fixed country table; other IPs are not
+// found
+type stubService struct {
+	domain.IPVerifierService
+	countries map[string]string
+}
+
+func (s *stubService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	result := &domain.LookupResult{IP: ip}
+	if country, ok := s.countries[ip]; ok {
+		result.Location = &domain.Location{Country: country, Continent: "EU"}
+	}
+	return result, nil
+}
+
+func (s *stubService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+	country, ok := s.countries[ip]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("Location not found for IP", nil)
+	}
+	return &domain.VerifyResult{
+		IP:        ip,
+		Country:   country,
+		Continent: "EU",
+		Allowed:   country == policy.AllowedCountries[0],
+	}, nil
+}
+
+func TestCombinedLog_ClientIP(t *testing.T) {
+	line := `192.0.2.1 - frank [10/Oct/2026:13:55:36 -0700] "GET /a\"b c HTTP/1.1" 200 2326 "-" "Mozilla/5.0 (X11)" "203.0.113.9, 10.0.0.1"`
+
+	tests := []struct {
+		field int
+		ip    string
+		err   string
+	}{
+		{field: 0, ip: "192.0.2.1"},
+		{field: 9, ip: "203.0.113.9"},
+		{field: 3, err: `invalid IP address "10/Oct/2026:13:55:36 -0700"`},
+		{field: 4, err: `invalid IP address "GET /a\\\"b c HTTP/1.1"`},
+		{field: 10, err: "line has no field 11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.field+1), func(t *testing.T) {
+			ip, err := (&combinedLog{field: tt.field}).clientIP(logEntry{line: []byte(line)})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.ip, ip)
+		})
+	}
+}
+
+func TestNormalizeIP(t *testing.T) {
+	tests := map[string]string{
+		"203.0.113.9":             "203.0.113.9",
+		" 203.0.113.9 , 10.0.0.1": "203.0.113.9",
+		"203.0.113.9:443":         "203.0.113.9",
+		"[2001:db8::1]:443":       "2001:db8::1",
+		"2001:DB8::1":             "2001:db8::1",
+	}
+	for value, want := range tests {
+		ip, err := normalizeIP(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, ip)
+	}
+
+	_, err := normalizeIP("-")
+	assert.Error(t, err)
+}
+
+func TestJSONLog(t *testing.T) {
+	log := &jsonLog{path: []string{"req", "client_ip"}, columns: enrichColumns[:2]}
+	country := "DE"
+	values := []*string{&country, nil}
+
+	ip, err := log.clientIP(logEntry{line: []byte(`{"req": {"client_ip": "2.2.2.2"}}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", ip)
+
+	_, err = log.clientIP(logEntry{line: []byte(`{"req": {}}`)})
+	assert.EqualError(t, err, `no "req.client_ip" field`)
+	_, err = log.clientIP(logEntry{line: []byte(`{"req": {"client_ip": 7}}`)})
+	assert.EqualError(t, err, `"req.client_ip" is not a string`)
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`{"z":1, "a": "x"}`, `{"z":1, "a": "x","geo_country":"DE","geo_continent":null}`},
+		{`{ }`, `{"geo_country":"DE","geo_continent":null}`},
+		{`not json`, `not json`},
+	}
+	for _, tt := range tests {
+		out, err := log.render(logEntry{line: []byte(tt.line)}, values)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want+"\n", string(out))
+	}
+}
+
+func TestNewLogFormat_Errors(t *testing.T) {
+	tests := []struct {
+		format string
+		field  string
+		input  string
+		err    string
+	}{
+		{"xml", "", "", `unknown log format "xml", want combined, jsonl or csv`},
+		{logCombined, "client", "", `--field for combined logs must be a field number, got "client"`},
+		{logCSV, "", "", "CSV log has no header"},
+		{logCSV, "ip", "time,client\n", `CSV header has no "ip" column`},
+	}
+
+	for _, tt := range tests {
+		_, err := newLogFormat(tt.format, tt.field, enrichColumns, strings.NewReader(tt.input), &bytes.Buffer{})
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestEnrichLog(t *testing.T) {
+	service := &stubService{countries: map[string]string{"2.2.2.2": "DE", "77.88.8.8": "RU"}}
+	policy := domain.Policy{AllowedCountries: []string{"DE"}}
+
+	tests := []struct {
+		name   string
+		format string
+		field  string
+		policy *domain.Policy
+		input  string
+		want   string
+		failed int
+	}{
+		{
+			name:   "combined",
+			format: logCombined,
+			input:  "2.2.2.2 - - [x] \"GET /\" 200\n77.88.8.8 - - [x] \"GET /\" 404\r\n8.8.8.8 - - [x] \"GET /\" 200",
+			policy: &policy,
+			want:   "2.2.2.2 - - [x] \"GET /\" 200 \"DE\" \"EU\" \"allow\"\n77.88.8.8 - - [x] \"GET /\" 404 \"RU\" \"EU\" \"deny\"\n8.8.8.8 - - [x] \"GET /\" 200 \"-\" \"-\" \"-\"\n",
+			failed: 1,
+		},
+		{
+			name:   "json lines without policy",
+			format: logJSONL,
+			field:  "ip",
+			input:  "{\"ip\":\"2.2.2.2\"}\n{\"ip\":\"8.8.8.8\"}\n",
+			want:   "{\"ip\":\"2.2.2.2\",\"geo_country\":\"DE\",\"geo_continent\":\"EU\"}\n{\"ip\":\"8.8.8.8\",\"geo_country\":null,\"geo_continent\":null}\n",
+		},
+		{
+			name:   "csv",
+			format: logCSV,
+			field:  "client",
+			policy: &policy,
+			input:  "client,path\n2.2.2.2,\"/a,b\"\nnope,/\n",
+			want:   "client,path,geo_country,geo_continent,geo_decision\n2.2.2.2,\"/a,b\",DE,EU,allow\nnope,/,,,\n",
+			failed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &enricher{ipService: service, policy: tt.policy}
+			var out bytes.Buffer
+			log, err := newLogFormat(tt.format, tt.field, e.columns(), strings.NewReader(tt.input), &out)
+			require.NoError(t, err)
+
+			failed, err := enrichLog(context.Background(), log, e, &out, 4)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+			assert.Equal(t, tt.failed, failed)
+		})
+	}
+}
+
+func TestEnrichLog_KeepsOrder(t *testing.T) {
+	service := &stubService{countries: map[string]string{"2.2.2.2": "DE"}}
+	e := &enricher{ipService: service}
+
+	var input, want strings.Builder
+	for i := range 5000 {
+		ip := "2.2.2.2"
+		suffix := ` "DE" "EU"`
+		if i%3 == 0 {
+			ip, suffix = "8.8.8.8", ` "-" "-"`
+		}
+		fmt.Fprintf(&input, "%s - - [x] \"GET /%d\" 200\n", ip, i)
+		fmt.Fprintf(&want, "%s - - [x] \"GET /%d\" 200%s\n", ip, i, suffix)
+	}
+
+	var out bytes.Buffer
+	log, err := newLogFormat(logCombined, "", e.columns(), strings.NewReader(input.String()), &out)
+	require.NoError(t, err)
+
+	_, err = enrichLog(context.Background(), log, e, &out, 8)
+	require.NoError(t, err)
+	assert.Equal(t, want.String(), out.String())
+}
+
+func TestEnrichLog_ReadError(t *testing.T) {
+	e := &enricher{ipService: &stubService{}}
+	var out bytes.Buffer
+	log, err := newLogFormat(logCSV, "ip", e.columns(), strings.NewReader("ip\n\"unterminated\n"), &out)
+	require.NoError(t, err)
+
+	_, err = enrichLog(context.Background(), log, e, &out, 2)
+	assert.Error(t, err)
+}
+
+func TestEnrich(t *testing.T) {
+	requireTestDB(t)
+
+	code, stdout, stderr := runCLI(t, "{\"remote_addr\": \"8.8.8.8\"}\n",
+		"enrich", "--db", testDB, "--log-format", "jsonl", "--allow", "US")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "{\"remote_addr\": \"8.8.8.8\",\"geo_country\":\"US\",\"geo_continent\":\"NA\",\"geo_decision\":\"allow\"}\n", stdout)
+
+	code, _, stderr = runCLI(t, "", "enrich", "--db", testDB, "a.log", "b.log")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "single log file")
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/repo"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listFlag collects comma-separated values; the flag may also be repeated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// asns parses the values as autonomous system numbers
+func (l listFlag) asns() ([]uint, error) {
+	asns := make([]uint, 0, len(l))
+	for _, item := range l {
+		n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(item), "AS"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ASN %q", item)
+		}
+		asns = append(asns, uint(n))
+	}
+	return asns, nil
+}
+
+// newFlagSet creates a flag set whose usage lists the flags of a command
+func (c *cli) newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: ip-verifier %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses flags that may appear before, after or between the
+// positional arguments, so "verify 8.8.8.8 --allow US" works, and returns the
+// positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// Everything after "--" is positional
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// databaseFlags selects the data files, defaulting to the environment
+// variables the API reads
+type databaseFlags struct {
+	country     string
+	city        string
+	asn         string
+	anonymousIP string
+	torExitList string
+}
+
+func (f *databaseFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.country, "db", getEnv("GEOIP_DB_PATH", "data/GeoLite2-Country.mmdb"), "GeoLite2-Country or City `file` ($GEOIP_DB_PATH)")
+	fs.StringVar(&f.city, "city-db", os.Getenv("GEOIP_CITY_DB_PATH"), "optional GeoLite2-City `file` ($GEOIP_CITY_DB_PATH)")
+	fs.StringVar(&f.asn, "asn-db", os.Getenv("GEOIP_ASN_DB_PATH"), "optional GeoLite2-ASN `file` ($GEOIP_ASN_DB_PATH)")
+	fs.StringVar(&f.anonymousIP, "anonymous-ip-db", os.Getenv("GEOIP_ANONYMOUS_IP_DB_PATH"), "optional GeoIP2-Anonymous-IP `file` ($GEOIP_ANONYMOUS_IP_DB_PATH)")
+	fs.StringVar(&f.torExitList, "tor-exit-list", os.Getenv("TOR_EXIT_LIST_PATH"), "optional Tor exit node list `file` ($TOR_EXIT_LIST_PATH)")
+}
+
+// compiler returns a policy compiler that rejects subdivision rules unless a
+// City database is given
+func (f *databaseFlags) compiler() *service.PolicyCompiler {
+	if f.city == "" {
+		return service.NewPolicyCompiler(service.WithoutSubdivisions())
+	}
+	return service.NewPolicyCompiler()
+}
+
+// open opens the data files and builds the verifier service on top of them.
+// The returned closer releases the databases.
+func (f *databaseFlags) open(opts ...service.Option) (domain.IPVerifierService, io.Closer, error) {
+	databases := &databaseSet{}
+
+	db, err := databases.open(f.country)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var repoOpts []repo.Option
+	optional := []struct {
+		path   string
+		option func(*repo.Database) repo.Option
+	}{
+		{f.city, repo.WithCityDatabase},
+		{f.asn, repo.WithASNDatabase},
+		{f.anonymousIP, repo.WithAnonymousIPDatabase},
+	}
+	for _, o := range optional {
+		if o.path == "" {
+			continue
+		}
+		optionalDB, err := databases.open(o.path)
+		if err != nil {
+			databases.Close()
+			return nil, nil, err
+		}
+		repoOpts = append(repoOpts, o.option(optionalDB))
+	}
+
+	if f.torExitList != "" {
+		torList, err := repo.OpenTorExitList(f.torExitList)
+		if err != nil {
+			databases.Close()
+			return nil, nil, err
+		}
+		repoOpts = append(repoOpts, repo.WithTorExitList(torList))
+	}
+
+	return service.NewIPVerifierService(repo.NewIPVerifierRepo(db, repoOpts...), opts...), databases, nil
+}
+
+// databaseSet closes every database it opened
+type databaseSet []*repo.Database
+
+func (s *databaseSet) open(path string) (*repo.Database, error) {
+	db, err := repo.OpenDatabase(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database %s: %w", path, err)
+	}
+	*s = append(*s, db)
+	return db, nil
+}
+
+func (s *databaseSet) Close() error {
+	var errs []error
+	for _, db := range *s {
+		errs = append(errs, db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// inputFlags selects where IPs are read from and how results are printed
+type inputFlags struct {
+	file   string
+	format string
+}
+
+func (f *inputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "file", "", "read IPs from `path`, one per line (\"-\" for stdin)")
+	fs.StringVar(&f.format, "format", formatTable, "output `format`: table, json or csv")
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
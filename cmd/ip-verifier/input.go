@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// forEachIP calls fn with each IP from args, then from file ("-" is stdin).
+// Without either, IPs are read from stdin. In files and on stdin blank lines
+// and lines starting with "#" are skipped, and only the first field of a line
+// is used, so annotated lists work as they are.
+func (c *cli) forEachIP(args []string, file string, fn func(ip string) error) error {
+	for _, ip := range args {
+		if err := fn(ip); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case file == "" && len(args) > 0:
+		return nil
+	case file == "" || file == "-":
+		return scanIPs(c.stdin, fn)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return scanIPs(f, fn)
+}
+
+func scanIPs(r io.Reader, fn func(ip string) error) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := fn(fields[0]); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+	return nil
+}
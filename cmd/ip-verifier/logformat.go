@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Log formats accepted by the enrich command
+const (
+	logCombined = "combined"
+	logJSONL    = "jsonl"
+	logCSV      = "csv"
+)
+
+// enrichColumns are the names of the fields the enrich command adds to JSON
+// and CSV logs; combined logs get the values only, in the same order
+var enrichColumns = []string{"geo_country", "geo_continent", "geo_decision"}
+
+// logEntry is one line of a line-based log or one record of a CSV log
+type logEntry struct {
+	line   []byte
+	record []string
+}
+
+// logFormat reads the entries of a log, finds their client IP and renders
+// them with the enrichment values appended. read is called from a single
+// goroutine; clientIP and render may be called concurrently.
+type logFormat interface {
+	read() (logEntry, error)
+	clientIP(entry logEntry) (string, error)
+	render(entry logEntry, values []*string) ([]byte, error)
+}
+
+// newLogFormat creates the reader for format. field selects the client IP:
+// a 1-based field number for combined logs, a dotted key path for JSON lines
+// and a column name for CSV. CSV logs start with a header, which is written
+// to out with the enrichment columns appended.
+func newLogFormat(format, field string, columns []string, in io.Reader, out io.Writer) (logFormat, error) {
+	switch format {
+	case logCombined:
+		if field == "" {
+			field = "1"
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("--field for combined logs must be a field number, got %q", field)
+		}
+		return &combinedLog{lines: lineReader{bufio.NewReader(in)}, field: n - 1}, nil
+	case logJSONL:
+		if field == "" {
+			field = "remote_addr"
+		}
+		return &jsonLog{lines: lineReader{bufio.NewReader(in)}, path: strings.Split(field, "."), columns: columns}, nil
+	case logCSV:
+		if field == "" {
+			field = "remote_addr"
+		}
+		return newCSVLog(in, out, field, columns)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, want combined, jsonl or csv", format)
+	}
+}
+
+// lineReader reads lines of any length, without the line ending
+type lineReader struct {
+	r *bufio.Reader
+}
+
+func (l lineReader) read() (logEntry, error) {
+	line, err := l.r.ReadBytes('\n')
+	if len(line) == 0 {
+		return logEntry{}, err
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return logEntry{}, err
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return logEntry{line: line}, nil
+}
+
+// combinedLog is the NCSA combined format written by nginx and Apache.
+// Fields are separated by spaces; a quoted string or a bracketed timestamp
+// counts as one field.
+type combinedLog struct {
+	lines lineReader
+	field int
+}
+
+func (l *combinedLog) read() (logEntry, error) {
+	return l.lines.read()
+}
+
+func (l *combinedLog) clientIP(entry logEntry) (string, error) {
+	line := string(entry.line)
+	for i := 0; ; i++ {
+		line = strings.TrimLeft(line, " ")
+		if line == "" {
+			return "", fmt.Errorf("line has no field %d", l.field+1)
+		}
+
+		var value string
+		switch line[0] {
+		case '"':
+			end := closingQuote(line)
+			if end < 0 {
+				return "", errors.New("unterminated quoted field")
+			}
+			value, line = line[1:end], line[end+1:]
+		case '[':
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return "", errors.New("unterminated bracketed field")
+			}
+			value, line = line[1:end], line[end+1:]
+		default:
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			value, line = line[:end], line[end:]
+		}
+
+		if i == l.field {
+			return normalizeIP(value)
+		}
+	}
+}
+
+// closingQuote returns the index of the quote ending the string that starts
+// at s[0], skipping backslash escapes, or -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *combinedLog) render(entry logEntry, values []*string) ([]byte, error) {
+	out := slices.Clip(entry.line)
+	for _, value := range values {
+		v := "-"
+		if value != nil && *value != "" {
+			v = *value
+		}
+		out = append(out, ` "`...)
+		out = append(out, v...)
+		out = append(out, '"')
+	}
+	return append(out, '\n'), nil
+}
+
+// jsonLog is a log with one JSON object per line
+type jsonLog struct {
+	lines   lineReader
+	path    []string
+	columns []string
+}
+
+func (l *jsonLog) read() (logEntry, error) {
+	return l.lines.read()
+}
+
+func (l *jsonLog) clientIP(entry logEntry) (string, error) {
+	raw := json.RawMessage(entry.line)
+	for _, key := range l.path {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return "", fmt.Errorf("not a JSON object: %w", err)
+		}
+		var ok bool
+		if raw, ok = object[key]; !ok {
+			return "", fmt.Errorf("no %q field", strings.Join(l.path, "."))
+		}
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%q is not a string", strings.Join(l.path, "."))
+	}
+	return normalizeIP(value)
+}
+
+// render appends the enrichment fields to the object without re-encoding
+// it, so the original key order and formatting are kept. Lines that are not
+// objects are written unchanged.
+func (l *jsonLog) render(entry logEntry, values []*string) ([]byte, error) {
+	line := bytes.TrimRight(entry.line, " \t")
+	if !bytes.HasPrefix(line, []byte("{")) || !bytes.HasSuffix(line, []byte("}")) {
+		return append(slices.Clip(entry.line), '\n'), nil
+	}
+
+	body := bytes.TrimSpace(line[1 : len(line)-1])
+	out := make([]byte, 0, len(line)+64)
+	out = append(out, '{')
+	out = append(out, body...)
+	for i, value := range values {
+		if i > 0 || len(body) > 0 {
+			out = append(out, ',')
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		out = strconv.AppendQuote(out, l.columns[i])
+		out = append(out, ':')
+		out = append(out, encoded...)
+	}
+	return append(out, '}', '\n'), nil
+}
+
+// csvLog is a CSV log with a header row naming the columns
+type csvLog struct {
+	reader *csv.Reader
+	column int
+}
+
+func newCSVLog(in io.Reader, out io.Writer, field string, columns []string) (*csvLog, error) {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV log has no header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	column := slices.Index(header, field)
+	if column < 0 {
+		return nil, fmt.Errorf("CSV header has no %q column", field)
+	}
+
+	w := csv.NewWriter(out)
+	if err := w.Write(append(slices.Clip(header), columns...)); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return &csvLog{reader: reader, column: column}, nil
+}
+
+func (l *csvLog) read() (logEntry, error) {
+	record, err := l.reader.Read()
+	return logEntry{record: record}, err
+}
+
+func (l *csvLog) clientIP(entry logEntry) (string, error) {
+	if l.column >= len(entry.record) {
+		return "", errors.New("record is missing the IP column")
+	}
+	return normalizeIP(entry.record[l.column])
+}
+
+func (l *csvLog) render(entry logEntry, values []*string) ([]byte, error) {
+	record := slices.Clip(entry.record)
+	for _, value := range values {
+		v := ""
+		if value != nil {
+			v = *value
+		}
+		record = append(record, v)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// normalizeIP extracts the client address from a log value. It takes the
+// first entry of an X-Forwarded-For style list and drops a port.
+func normalizeIP(value string) (string, error) {
+	value, _, _ = strings.Cut(value, ",")
+	value = strings.TrimSpace(value)
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.String(), nil
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().String(), nil
+	}
+	return "", fmt.Errorf("invalid IP address %q", value)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupRecord matches the body of GET /api/v1/ip-verifier/lookup/:ip, with
+// Error set instead of the data when the IP could not be looked up
+type lookupRecord struct {
+	IP                 string       `json:"ip"`
+	Country            string       `json:"country,omitempty"`
+	Continent          string       `json:"continent,omitempty"`
+	RegisteredCountry  string       `json:"registered_country,omitempty"`
+	RepresentedCountry string       `json:"represented_country,omitempty"`
+	Subdivisions       []string     `json:"subdivisions,omitempty"`
+	City               string       `json:"city,omitempty"`
+	PostalCode         string       `json:"postal_code,omitempty"`
+	Coordinates        *coordinates `json:"coordinates,omitempty"`
+	ASN                uint         `json:"asn,omitempty"`
+	ASOrg              string       `json:"as_org,omitempty"`
+	IsAnonymous        bool         `json:"is_anonymous,omitempty"`
+	IsVPN              bool         `json:"is_vpn,omitempty"`
+	IsTor              bool         `json:"is_tor,omitempty"`
+	IsHosting          bool         `json:"is_hosting,omitempty"`
+	IsPublicProxy      bool         `json:"is_public_proxy,omitempty"`
+	RangeType          string       `json:"range_type,omitempty"`
+	DatabaseBuild      string       `json:"database_build,omitempty"`
+	Error              string       `json:"error,omitempty"`
+}
+
+type coordinates struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	AccuracyRadius uint16  `json:"accuracy_radius_km,omitempty"`
+}
+
+var lookupHeader = []string{
+	"ip", "country", "continent", "registered_country", "subdivisions", "city",
+	"asn", "as_org", "anonymity", "range_type", "error",
+}
+
+func (c *cli) lookup(args []string) int {
+	fs := c.newFlagSet("lookup", "lookup [flags] [ip ...]")
+	var databases databaseFlags
+	databases.register(fs)
+	var input inputFlags
+	input.register(fs)
+
+	ips, err := parseArgs(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	ipService, closer, err := databases.open()
+	if err != nil {
+		return c.fail(err)
+	}
+	defer closer.Close()
+
+	out, err := newRecordWriter(c.stdout, input.format, lookupHeader)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	code := exitOK
+	err = c.forEachIP(ips, input.file, func(ip string) error {
+		record := lookupRecord{IP: ip}
+		result, err := ipService.LookupIP(context.Background(), ip)
+		if err != nil {
+			record.Error = apperrors.GetMessage(err)
+			code = exitError
+		} else {
+			record = newLookupRecord(result)
+		}
+		return out.Write(record, record.row())
+	})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+	return code
+}
+
+func newLookupRecord(result *domain.LookupResult) lookupRecord {
+	record := lookupRecord{
+		IP:        result.IP,
+		RangeType: string(result.RangeType),
+	}
+	if l := result.Location; l != nil {
+		record.Country = l.Country
+		record.Continent = l.Continent
+		record.RegisteredCountry = l.RegisteredCountry
+		record.RepresentedCountry = l.RepresentedCountry
+		record.Subdivisions = l.Subdivisions
+		record.City = l.City
+		record.PostalCode = l.PostalCode
+		record.Coordinates = toCoordinates(l.Coordinates)
+		record.DatabaseBuild = formatBuild(l.DatabaseBuild)
+	}
+	if result.ASN != nil {
+		record.ASN = result.ASN.Number
+		record.ASOrg = result.ASN.Organization
+	}
+	if a := result.Anonymity; a != nil {
+		record.IsAnonymous = a.IsAnonymous
+		record.IsVPN = a.IsVPN
+		record.IsTor = a.IsTor
+		record.IsHosting = a.IsHosting
+		record.IsPublicProxy = a.IsPublicProxy
+	}
+	return record
+}
+
+func (r lookupRecord) row() []string {
+	return []string{
+		r.IP, r.Country, r.Continent, r.RegisteredCountry, strings.Join(r.Subdivisions, "-"), r.City,
+		formatASN(r.ASN), r.ASOrg, formatAnonymity(domain.Anonymity{
+			IsAnonymous:   r.IsAnonymous,
+			IsVPN:         r.IsVPN,
+			IsTor:         r.IsTor,
+			IsHosting:     r.IsHosting,
+			IsPublicProxy: r.IsPublicProxy,
+		}), r.RangeType, r.Error,
+	}
+}
+
+func toCoordinates(c *domain.Coordinates) *coordinates {
+	if c == nil {
+		return nil
+	}
+	return &coordinates{
+		Latitude:       c.Latitude,
+		Longitude:      c.Longitude,
+		AccuracyRadius: c.AccuracyRadius,
+	}
+}
+
+// formatBuild renders a database build time as RFC 3339, or "" when unset
+func formatBuild(build time.Time) string {
+	if build.IsZero() {
+		return ""
+	}
+	return build.UTC().Format(time.RFC3339)
+}
+
+// formatASN renders an ASN, or "" when there is none
+func formatASN(asn uint) string {
+	if asn == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(asn), 10)
+}
+
+// formatAnonymity lists the anonymising services an IP belongs to, using
+// the names accepted by --deny-anonymity
+func formatAnonymity(a domain.Anonymity) string {
+	var types []string
+	for _, t := range []struct {
+		set  bool
+		name domain.AnonymityType
+	}{
+		{a.IsVPN, domain.AnonymityVPN},
+		{a.IsTor, domain.AnonymityTor},
+		{a.IsHosting, domain.AnonymityHosting},
+		{a.IsPublicProxy, domain.AnonymityPublicProxy},
+	} {
+		if t.set {
+			types = append(types, string(t.name))
+		}
+	}
+	if len(types) == 0 && a.IsAnonymous {
+		return "anonymous"
+	}
+	return strings.Join(types, ",")
+}
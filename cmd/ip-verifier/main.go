@@ -0,0 +1,95 @@
+// Command ip-verifier looks up and verifies IP addresses from the shell. It
+// opens the GeoIP databases directly and runs the same service layer as the
+// API, so both always reach the same decision.
+//
+// Usage:
+//
+//	ip-verifier lookup [flags] [ip ...]
+//	ip-verifier verify [flags] [ip ...]
+//	ip-verifier enrich [flags] [log file]
+//
+// lookup and verify take IPs from the arguments, from the file given with
+// --file, or from stdin when neither is given. enrich reads a combined,
+// JSON lines or CSV access log from the file or stdin and writes it back with
+// the location and policy decision of each entry's client IP appended.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes, following grep: 1 means the check ran and something was
+// denied, 2 means the check itself failed for at least one IP
+const (
+	exitOK     = 0
+	exitDenied = 1
+	exitError  = 2
+)
+
+// cli holds the streams a command reads from and writes to
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a subcommand of the CLI
+type command struct {
+	name    string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+var commands = []command{
+	{"lookup", "Show the location, ASN and anonymity data of IPs", (*cli).lookup},
+	{"verify", "Check IPs against a policy", (*cli).verify},
+	{"enrich", "Add country, continent and decision fields to an access log", (*cli).enrich},
+}
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run dispatches args to a subcommand and returns the exit code
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		c.usage()
+		return exitError
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		c.usage()
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(c, args[1:])
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "ip-verifier: unknown command %q\n\n", args[0])
+	c.usage()
+	return exitError
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "Usage: ip-verifier <command> [flags] [ip ...]")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, `Run "ip-verifier <command> -h" for the flags of a command.`)
+}
+
+// fail reports an error that stops a command and returns the exit code
+func (c *cli) fail(err error) int {
+	fmt.Fprintf(c.stderr, "ip-verifier: %v\n", err)
+	return exitError
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDB = "../../data/GeoLite2-Country.mmdb"
+
+// runCLI runs the CLI with args and stdin, returning the exit code and output
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	code := c.run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func requireTestDB(t *testing.T) {
+	t.Helper()
+	if _, err := os.Stat(testDB); err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCLI(t, "")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "Usage: ip-verifier <command>")
+
+	code, _, _ = runCLI(t, "", "help")
+	assert.Equal(t, exitOK, code)
+
+	code, _, stderr = runCLI(t, "", "resolve", "8.8.8.8")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, `unknown command "resolve"`)
+
+	code, _, stderr = runCLI(t, "", "verify", "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "-allow")
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		allow      []string
+		format     string
+	}{
+		{"flags first", []string{"--allow", "US", "8.8.8.8"}, []string{"8.8.8.8"}, []string{"US"}, formatTable},
+		{"flags last", []string{"8.8.8.8", "1.1.1.1", "--allow", "US,CA", "--format=json"}, []string{"8.8.8.8", "1.1.1.1"}, []string{"US", "CA"}, formatJSON},
+		{"interleaved and repeated", []string{"8.8.8.8", "--allow", "US", "1.1.1.1", "--allow", "CA"}, []string{"8.8.8.8", "1.1.1.1"}, []string{"US", "CA"}, formatTable},
+		{"double dash", []string{"--allow", "US", "--", "8.8.8.8", "--format"}, []string{"8.8.8.8", "--format"}, []string{"US"}, formatTable},
+		{"no positional", []string{"--format", "csv"}, nil, nil, formatCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			var allow listFlag
+			fs.Var(&allow, "allow", "")
+			format := fs.String("format", formatTable, "")
+
+			positional, err := parseArgs(fs, tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.positional, positional)
+			assert.Equal(t, tt.allow, []string(allow))
+			assert.Equal(t, tt.format, *format)
+		})
+	}
+}
+
+func TestListFlag_ASNs(t *testing.T) {
+	var l listFlag
+	require.NoError(t, l.Set("15169, AS13335,as64500"))
+	asns, err := l.asns()
+	require.NoError(t, err)
+	assert.Equal(t, []uint{15169, 13335, 64500}, asns)
+
+	l = listFlag{"GOOGLE"}
+	_, err = l.asns()
+	assert.EqualError(t, err, `invalid ASN "GOOGLE"`)
+}
+
+func TestForEachIP(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ips.txt")
+	require.NoError(t, os.WriteFile(file, []byte("# office\n203.0.113.1  branch\n\n  198.51.100.7\n"), 0o644))
+
+	collect := func(c *cli, args []string, file string) []string {
+		var ips []string
+		require.NoError(t, c.forEachIP(args, file, func(ip string) error {
+			ips = append(ips, ip)
+			return nil
+		}))
+		return ips
+	}
+	c := &cli{stdin: strings.NewReader("192.0.2.1\n#skip\n192.0.2.2 x\n")}
+
+	assert.Equal(t, []string{"8.8.8.8"}, collect(c, []string{"8.8.8.8"}, ""))
+	assert.Equal(t, []string{"8.8.8.8", "203.0.113.1", "198.51.100.7"}, collect(c, []string{"8.8.8.8"}, file))
+	assert.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, collect(c, nil, ""))
+
+	err := c.forEachIP(nil, filepath.Join(t.TempDir(), "missing.txt"), func(string) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestRecordWriter(t *testing.T) {
+	header := []string{"ip", "country", "error"}
+	write := func(format string) string {
+		var buf bytes.Buffer
+		w, err := newRecordWriter(&buf, format, header)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(map[string]string{"ip": "8.8.8.8"}, []string{"8.8.8.8", "US", ""}))
+		require.NoError(t, w.Write(map[string]string{"ip": "x"}, []string{"x", "", "Invalid IP address, try again"}))
+		require.NoError(t, w.Close())
+		return buf.String()
+	}
+
+	assert.Equal(t, "{\"ip\":\"8.8.8.8\"}\n{\"ip\":\"x\"}\n", write(formatJSON))
+	assert.Equal(t, "ip,country,error\n8.8.8.8,US,\nx,,\"Invalid IP address, try again\"\n", write(formatCSV))
+	assert.Equal(t, ""+
+		"IP       COUNTRY  ERROR\n"+
+		"8.8.8.8  US       -\n"+
+		"x        -        Invalid IP address, try again\n", write(formatTable))
+
+	_, err := newRecordWriter(io.Discard, "xml", header)
+	assert.EqualError(t, err, `unknown format "xml", want table, json or csv`)
+}
+
+func TestLookup(t *testing.T) {
+	requireTestDB(t)
+
+	code, stdout, _ := runCLI(t, "", "lookup", "--db", testDB, "--format", "json", "8.8.8.8", "10.1.2.3", "not-an-ip")
+	assert.Equal(t, exitError, code, "an invalid IP fails the run")
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+
+	var record lookupRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "US", record.Country)
+	assert.NotEmpty(t, record.DatabaseBuild)
+	assert.JSONEq(t, `{"ip": "10.1.2.3", "range_type": "private"}`, lines[1])
+	assert.JSONEq(t, `{"ip": "not-an-ip", "error": "Invalid IP address"}`, lines[2])
+}
+
+func TestVerify(t *testing.T) {
+	requireTestDB(t)
+
+	tests := []struct {
+		name   string
+		stdin  string
+		args   []string
+		code   int
+		stdout string
+	}{
+		{
+			name:   "allowed",
+			args:   []string{"8.8.8.8", "--allow", "US,CA"},
+			code:   exitOK,
+			stdout: "ip,allowed,reason,country,asn,anonymity,matched_rule,range_type,error\n8.8.8.8,true,country_allowed,US,,,allowed_countries:US,,\n",
+		},
+		{
+			name:   "denied from stdin",
+			stdin:  "8.8.8.8\n10.0.0.1\n",
+			args:   []string{"--deny", "US"},
+			code:   exitDenied,
+			stdout: "ip,allowed,reason,country,asn,anonymity,matched_rule,range_type,error\n8.8.8.8,false,country_denied,US,,,denied_countries:US,,\n10.0.0.1,false,unknown_location,,,,unknown_action:deny,private,\n",
+		},
+		{
+			name:   "invalid IP",
+			args:   []string{"8.8.8.8", "bad", "--deny", "US"},
+			code:   exitError,
+			stdout: "ip,allowed,reason,country,asn,anonymity,matched_rule,range_type,error\n8.8.8.8,false,country_denied,US,,,denied_countries:US,,\nbad,,,,,,,,Invalid IP address\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"verify", "--db", testDB, "--asn-db", "", "--anonymous-ip-db", "", "--format", "csv"}, tt.args...)
+			code, stdout, stderr := runCLI(t, tt.stdin, args...)
+			assert.Equal(t, tt.code, code, stderr)
+			assert.Equal(t, tt.stdout, stdout)
+		})
+	}
+}
+
+func TestVerify_Policy(t *testing.T) {
+	requireTestDB(t)
+
+	policyFile := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`{"policies": [{"id": "us-only", "allowed_countries": ["US"]}]}`), 0o644))
+
+	code, stdout, stderr := runCLI(t, "", "verify", "--db", testDB, "--policy-file", policyFile, "--policy", "us-only", "--format", "json", "8.8.8.8")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `"reason":"country_allowed"`)
+
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"no policy", []string{"8.8.8.8"}, "one of --policy, --allow"},
+		{"policy and rules", []string{"--policy", "us-only", "--policy-file", policyFile, "--allow", "CA", "8.8.8.8"}, "cannot be combined"},
+		{"unknown policy", []string{"--policy", "eu-only", "--policy-file", policyFile, "8.8.8.8"}, `policy "eu-only"`},
+		{"invalid rules", []string{"--allow-cidr", "10.0.0.0/33", "8.8.8.8"}, "ip-verifier: "},
+		{"invalid ASN", []string{"--deny-asn", "GOOGLE", "8.8.8.8"}, `invalid ASN "GOOGLE"`},
+		{"missing database", []string{"--db", filepath.Join(t.TempDir(), "missing.mmdb"), "--allow", "US", "8.8.8.8"}, "open database"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, "", append([]string{"verify", "--db", testDB}, tt.args...)...)
+			assert.Equal(t, exitError, code)
+			assert.Empty(t, stdout)
+			assert.Contains(t, stderr, tt.err)
+		})
+	}
+}
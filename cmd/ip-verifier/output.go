@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// recordWriter prints one record per IP. JSON output writes value, one
+// object per line; CSV and table output write row under the header.
+type recordWriter interface {
+	Write(value any, row []string) error
+	Close() error
+}
+
+func newRecordWriter(w io.Writer, format string, header []string) (recordWriter, error) {
+	switch format {
+	case formatJSON:
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		t := &tableWriter{w: tw}
+		if err := t.writeRow(header, strings.ToUpper); err != nil {
+			return nil, err
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, want table, json or csv", format)
+	}
+}
+
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonWriter) Write(value any, row []string) error {
+	return j.enc.Encode(value)
+}
+
+func (j *jsonWriter) Close() error {
+	return nil
+}
+
+// csvWriter flushes after every row so results stream through pipes
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(value any, row []string) error {
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// tableWriter aligns columns, so nothing is printed until Close
+type tableWriter struct {
+	w *tabwriter.Writer
+}
+
+func (t *tableWriter) Write(value any, row []string) error {
+	return t.writeRow(row, func(cell string) string {
+		if cell == "" {
+			return "-"
+		}
+		return cell
+	})
+}
+
+func (t *tableWriter) writeRow(row []string, format func(string) string) error {
+	cells := make([]string, len(row))
+	for i, cell := range row {
+		cells[i] = format(cell)
+	}
+	_, err := fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+	return err
+}
+
+func (t *tableWriter) Close() error {
+	return t.w.Flush()
+}
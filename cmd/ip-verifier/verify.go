@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/repo"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"os"
+	"strconv"
+)
+
+// verifyRecord matches an item of the POST /api/v1/ip-verifier/batch
+// response: the verification result, or Error when the IP could not be
+// verified
+type verifyRecord struct {
+	IP                 string       `json:"ip"`
+	Country            string       `json:"country,omitempty"`
+	Continent          string       `json:"continent,omitempty"`
+	RegisteredCountry  string       `json:"registered_country,omitempty"`
+	RepresentedCountry string       `json:"represented_country,omitempty"`
+	Subdivisions       []string     `json:"subdivisions,omitempty"`
+	City               string       `json:"city,omitempty"`
+	PostalCode         string       `json:"postal_code,omitempty"`
+	Coordinates        *coordinates `json:"coordinates,omitempty"`
+	ASN                uint         `json:"asn,omitempty"`
+	ASOrg              string       `json:"as_org,omitempty"`
+	IsVPN              bool         `json:"is_vpn,omitempty"`
+	IsTor              bool         `json:"is_tor,omitempty"`
+	IsHosting          bool         `json:"is_hosting,omitempty"`
+	IsPublicProxy      bool         `json:"is_public_proxy,omitempty"`
+	Allowed            bool         `json:"allowed"`
+	Reason             string       `json:"reason,omitempty"`
+	RangeType          string       `json:"range_type,omitempty"`
+	MatchedGroup       string       `json:"matched_group,omitempty"`
+	MatchedRule        *matchedRule `json:"matched_rule,omitempty"`
+	DatabaseBuild      string       `json:"database_build,omitempty"`
+	Error              string       `json:"error,omitempty"`
+}
+
+type matchedRule struct {
+	List  string `json:"list"`
+	Entry string `json:"entry"`
+}
+
+var verifyHeader = []string{
+	"ip", "allowed", "reason", "country", "asn", "anonymity", "matched_rule", "range_type", "error",
+}
+
+// policyFlags builds the policy to verify against, either inline from the
+// rule flags or by name from a policy file
+type policyFlags struct {
+	id             string
+	file           string
+	allowCountries listFlag
+	denyCountries  listFlag
+	allowASNs      listFlag
+	denyASNs       listFlag
+	allowCIDRs     listFlag
+	denyCIDRs      listFlag
+	denyAnonymity  listFlag
+	unknownAction  string
+	allowInternal  bool
+	matchMode      string
+}
+
+func (f *policyFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.id, "policy", "", "verify against the named policy `id` from the policy file")
+	fs.StringVar(&f.file, "policy-file", os.Getenv("POLICY_FILE"), "policy `file` for --policy ($POLICY_FILE)")
+	fs.Var(&f.allowCountries, "allow", "allowed countries, continents or groups (e.g. US,CA,group:EU)")
+	fs.Var(&f.denyCountries, "deny", "denied countries, continents or groups")
+	fs.Var(&f.allowASNs, "allow-asn", "allowed autonomous system numbers")
+	fs.Var(&f.denyASNs, "deny-asn", "denied autonomous system numbers")
+	fs.Var(&f.allowCIDRs, "allow-cidr", "allowed IP ranges")
+	fs.Var(&f.denyCIDRs, "deny-cidr", "denied IP ranges")
+	fs.Var(&f.denyAnonymity, "deny-anonymity", "denied anonymity types: vpn, tor, hosting, public_proxy")
+	fs.StringVar(&f.unknownAction, "unknown-action", "", "`action` for IPs without a country: deny, allow or error")
+	fs.BoolVar(&f.allowInternal, "allow-internal", false, "allow private, CGNAT, loopback and link-local addresses")
+	fs.StringVar(&f.matchMode, "match-mode", "", "`mode` selecting the countries the rules apply to: physical, registered, any or all")
+}
+
+// empty reports whether no policy flag was given
+func (f *policyFlags) empty() bool {
+	return f.id == "" && len(f.allowCountries) == 0 && len(f.denyCountries) == 0 &&
+		len(f.allowASNs) == 0 && len(f.denyASNs) == 0 &&
+		len(f.allowCIDRs) == 0 && len(f.denyCIDRs) == 0 && len(f.denyAnonymity) == 0 &&
+		f.unknownAction == "" && !f.allowInternal && f.matchMode == ""
+}
+
+// policy returns the named or inline policy, validated the same way the API
+// validates it. As with policy_id in the API, --policy cannot be combined
+// with rule flags but --unknown-action overrides the named policy's action.
+// A named policy is compiled into compiler.
+func (f *policyFlags) policy(ctx context.Context, compiler *service.PolicyCompiler) (domain.Policy, error) {
+	allowedASNs, err := f.allowASNs.asns()
+	if err != nil {
+		return domain.Policy{}, err
+	}
+	deniedASNs, err := f.denyASNs.asns()
+	if err != nil {
+		return domain.Policy{}, err
+	}
+
+	inline := domain.Policy{
+		AllowedCountries: f.allowCountries,
+		DeniedCountries:  f.denyCountries,
+		AllowedASNs:      allowedASNs,
+		DeniedASNs:       deniedASNs,
+		AllowedCIDRs:     f.allowCIDRs,
+		DeniedCIDRs:      f.denyCIDRs,
+		UnknownAction:    domain.UnknownAction(f.unknownAction),
+		AllowInternal:    f.allowInternal,
+		MatchMode:        domain.MatchMode(f.matchMode),
+	}
+	for _, t := range f.denyAnonymity {
+		inline.DeniedAnonymity = append(inline.DeniedAnonymity, domain.AnonymityType(t))
+	}
+
+	if f.id == "" {
+		if !inline.HasRules() {
+			return domain.Policy{}, errors.New("one of --policy, --allow, --deny, --allow-asn, --deny-asn, --allow-cidr, --deny-cidr or --deny-anonymity is required")
+		}
+		return inline, compiler.Validate(inline)
+	}
+
+	if inline.HasRules() || inline.AllowInternal || inline.MatchMode != "" {
+		return domain.Policy{}, errors.New("--policy cannot be combined with policy rule flags")
+	}
+	if f.file == "" {
+		return domain.Policy{}, errors.New("--policy needs --policy-file or $POLICY_FILE")
+	}
+
+	policies, err := repo.LoadPolicyFile(f.file)
+	if err != nil {
+		return domain.Policy{}, err
+	}
+	policyService := service.NewPolicyService(repo.NewPolicyRepo(), compiler)
+	for _, policy := range policies {
+		if _, err := policyService.CreatePolicy(ctx, policy); err != nil {
+			return domain.Policy{}, fmt.Errorf("policy %q: %w", policy.ID, err)
+		}
+	}
+
+	policy, err := policyService.GetPolicy(ctx, f.id)
+	if err != nil {
+		return domain.Policy{}, fmt.Errorf("policy %q: %w", f.id, err)
+	}
+	if inline.UnknownAction != "" {
+		policy.UnknownAction = inline.UnknownAction
+	}
+	return *policy, compiler.Validate(*policy)
+}
+
+func (c *cli) verify(args []string) int {
+	fs := c.newFlagSet("verify", "verify [flags] [ip ...]")
+	var databases databaseFlags
+	databases.register(fs)
+	var input inputFlags
+	input.register(fs)
+	var policyOpts policyFlags
+	policyOpts.register(fs)
+
+	ips, err := parseArgs(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	ctx := context.Background()
+	compiler := databases.compiler()
+	policy, err := policyOpts.policy(ctx, compiler)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	ipService, closer, err := databases.open(service.WithPolicyCompiler(compiler))
+	if err != nil {
+		return c.fail(err)
+	}
+	defer closer.Close()
+
+	out, err := newRecordWriter(c.stdout, input.format, verifyHeader)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	denied, failed := false, false
+	err = c.forEachIP(ips, input.file, func(ip string) error {
+		record := verifyRecord{IP: ip}
+		result, err := ipService.VerifyIP(ctx, ip, policy)
+		if err != nil {
+			record.Error = apperrors.GetMessage(err)
+			failed = true
+		} else {
+			record = newVerifyRecord(result)
+			denied = denied || !result.Allowed
+		}
+		return out.Write(record, record.row())
+	})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	switch {
+	case err != nil:
+		return c.fail(err)
+	case failed:
+		return exitError
+	case denied:
+		return exitDenied
+	default:
+		return exitOK
+	}
+}
+
+func newVerifyRecord(result *domain.VerifyResult) verifyRecord {
+	record := verifyRecord{
+		IP:                 result.IP,
+		Country:            result.Country,
+		Continent:          result.Continent,
+		RegisteredCountry:  result.RegisteredCountry,
+		RepresentedCountry: result.RepresentedCountry,
+		Subdivisions:       result.Subdivisions,
+		City:               result.City,
+		PostalCode:         result.PostalCode,
+		Coordinates:        toCoordinates(result.Coordinates),
+		ASN:                result.ASN,
+		ASOrg:              result.ASOrg,
+		IsVPN:              result.Anonymity.IsVPN,
+		IsTor:              result.Anonymity.IsTor,
+		IsHosting:          result.Anonymity.IsHosting,
+		IsPublicProxy:      result.Anonymity.IsPublicProxy,
+		Allowed:            result.Allowed,
+		Reason:             string(result.Reason),
+		RangeType:          string(result.RangeType),
+		MatchedGroup:       result.MatchedGroup,
+		DatabaseBuild:      formatBuild(result.DatabaseBuild),
+	}
+	if rule := result.MatchedRule; rule != nil {
+		record.MatchedRule = &matchedRule{List: rule.List, Entry: rule.Entry}
+	}
+	return record
+}
+
+func (r verifyRecord) row() []string {
+	allowed, rule := "", ""
+	if r.Error == "" {
+		allowed = strconv.FormatBool(r.Allowed)
+	}
+	if r.MatchedRule != nil {
+		rule = r.MatchedRule.List + ":" + r.MatchedRule.Entry
+	}
+	return []string{
+		r.IP, allowed, r.Reason, r.Country, formatASN(r.ASN), formatAnonymity(domain.Anonymity{
+			IsVPN:         r.IsVPN,
+			IsTor:         r.IsTor,
+			IsHosting:     r.IsHosting,
+			IsPublicProxy: r.IsPublicProxy,
+		}), rule, r.RangeType, r.Error,
+	}
+}
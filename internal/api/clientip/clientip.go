@@ -0,0 +1,200 @@
+// Package clientip determines the address of the client behind a chain of
+// reverse proxies.
+package clientip
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Source names where a client IP was taken from
+type Source string
+
+const (
+	SourceRemoteAddr    Source = "remote_addr"
+	SourceForwarded     Source = "forwarded"
+	SourceXForwardedFor Source = "x-forwarded-for"
+	SourceXRealIP       Source = "x-real-ip"
+)
+
+// ErrNoClientIP is returned when the client address cannot be determined
+var ErrNoClientIP = errors.New("client IP could not be determined")
+
+// Resolver extracts client IPs from requests. Only the one forwarding header
+// the trusted proxies set is read, and only when the direct peer is a trusted
+// proxy. Any other forwarding header may have been sent by the client and
+// passed through untouched, so it is ignored. A forwarding chain is walked
+// from the nearest hop outwards, stopping at the first address that is not a
+// trusted proxy. Entries further out were supplied by untrusted parties and
+// are never used.
+type Resolver struct {
+	trusted []netip.Prefix
+	header  Source
+}
+
+// NewResolver creates a Resolver that reads the client IP from header
+// (SourceForwarded, SourceXForwardedFor or SourceXRealIP) when the request
+// comes from a proxy in the given CIDRs. Bare addresses are treated as single
+// hosts. With no trusted proxies only the connection's remote address is used.
+func NewResolver(trustedProxies []string, header Source) (*Resolver, error) {
+	switch header {
+	case SourceForwarded, SourceXForwardedFor, SourceXRealIP:
+	default:
+		return nil, fmt.Errorf("invalid client IP header %q, expected forwarded, x-forwarded-for or x-real-ip", header)
+	}
+
+	r := &Resolver{header: header}
+	for _, entry := range trustedProxies {
+		prefix, err := ParsePrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+	return r, nil
+}
+
+// ParsePrefix parses a trusted proxy entry, either a CIDR or a bare address
+func ParsePrefix(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Resolve returns the client IP of req and the source it was taken from. The
+// remote address is used when the peer is untrusted or the configured header
+// is missing.
+func (r *Resolver) Resolve(req *http.Request) (netip.Addr, Source, error) {
+	peer, err := parseHost(req.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, "", fmt.Errorf("%w: invalid remote address %q", ErrNoClientIP, req.RemoteAddr)
+	}
+	if !r.isTrusted(peer) {
+		return peer, SourceRemoteAddr, nil
+	}
+
+	switch r.header {
+	case SourceForwarded:
+		if values := req.Header.Values("Forwarded"); len(values) > 0 {
+			hops, err := parseForwarded(values)
+			if err != nil {
+				return netip.Addr{}, "", err
+			}
+			return r.walk(hops, peer), SourceForwarded, nil
+		}
+	case SourceXForwardedFor:
+		if values := req.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			hops, err := parseList(values)
+			if err != nil {
+				return netip.Addr{}, "", err
+			}
+			return r.walk(hops, peer), SourceXForwardedFor, nil
+		}
+	case SourceXRealIP:
+		if value := strings.TrimSpace(req.Header.Get("X-Real-IP")); value != "" {
+			addr, err := parseHost(value)
+			if err != nil {
+				return netip.Addr{}, "", fmt.Errorf("%w: invalid X-Real-IP %q", ErrNoClientIP, value)
+			}
+			return addr, SourceXRealIP, nil
+		}
+	}
+
+	return peer, SourceRemoteAddr, nil
+}
+
+// walk returns the first untrusted hop counting from the nearest proxy, or
+// the outermost hop when every hop is trusted
+func (r *Resolver) walk(hops []netip.Addr, peer netip.Addr) netip.Addr {
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		client = hops[i]
+		if !r.isTrusted(client) {
+			break
+		}
+	}
+	return client
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseList parses comma-separated X-Forwarded-For values in order
+func parseList(values []string) ([]netip.Addr, error) {
+	var hops []netip.Addr
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			addr, err := parseHost(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid X-Forwarded-For entry %q", ErrNoClientIP, strings.TrimSpace(entry))
+			}
+			hops = append(hops, addr)
+		}
+	}
+	return hops, nil
+}
+
+// parseForwarded parses the for= parameters of RFC 7239 Forwarded values in
+// order. Obfuscated or "unknown" identifiers cannot be verified and are
+// rejected.
+func parseForwarded(values []string) ([]netip.Addr, error) {
+	var hops []netip.Addr
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			node := ""
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					node = strings.Trim(val, `"`)
+				}
+			}
+			if node == "" {
+				return nil, fmt.Errorf("%w: Forwarded element without for= %q", ErrNoClientIP, strings.TrimSpace(element))
+			}
+
+			addr, err := parseHost(node)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid Forwarded node %q", ErrNoClientIP, node)
+			}
+			hops = append(hops, addr)
+		}
+	}
+	return hops, nil
+}
+
+// parseHost parses an address with an optional port, such as "192.0.2.1",
+// "192.0.2.1:8080", "2001:db8::1" or "[2001:db8::1]:8080"
+func parseHost(value string) (netip.Addr, error) {
+	value = strings.TrimSpace(value)
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap().WithZone(""), nil
+	}
+
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap().WithZone(""), nil
+}
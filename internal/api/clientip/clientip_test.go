@@ -0,0 +1,140 @@
+package clientip
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "2001:db8:ffff::/48", "192.0.2.1"}
+
+	tests := []struct {
+		name       string
+		header     Source
+		remoteAddr string
+		headers    map[string][]string
+		expectedIP string
+		source     Source
+	}{
+		{"direct connection", SourceXForwardedFor, "203.0.113.7:5000", nil, "203.0.113.7", SourceRemoteAddr},
+		{"untrusted peer headers ignored", SourceXForwardedFor, "203.0.113.7:5000", map[string][]string{
+			"X-Forwarded-For": {"1.1.1.1"},
+			"X-Real-Ip":       {"1.1.1.1"},
+		}, "203.0.113.7", SourceRemoteAddr},
+		{"trusted peer without headers", SourceXForwardedFor, "10.0.0.2:5000", nil, "10.0.0.2", SourceRemoteAddr},
+		{"x-forwarded-for single hop", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Forwarded-For": {"8.8.8.8"},
+		}, "8.8.8.8", SourceXForwardedFor},
+		{"x-forwarded-for skips trusted hops", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Forwarded-For": {"8.8.8.8, 10.1.1.1", "192.0.2.1"},
+		}, "8.8.8.8", SourceXForwardedFor},
+		{"x-forwarded-for spoofed entries ignored", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Forwarded-For": {"1.1.1.1, 9.9.9.9, 8.8.8.8"},
+		}, "8.8.8.8", SourceXForwardedFor},
+		{"x-forwarded-for all trusted", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Forwarded-For": {"10.9.9.9, 10.1.1.1"},
+		}, "10.9.9.9", SourceXForwardedFor},
+		{"x-forwarded-for with ports", SourceXForwardedFor, "[2001:db8:ffff::1]:443", map[string][]string{
+			"X-Forwarded-For": {"[2001:4860::8888]:1234"},
+		}, "2001:4860::8888", SourceXForwardedFor},
+		{"x-forwarded-for zoned address with port", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Forwarded-For": {"[fe80::1%eth0]:443"},
+		}, "fe80::1", SourceXForwardedFor},
+		{"x-forwarded-for zoned address", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Forwarded-For": {"fe80::1%eth0"},
+		}, "fe80::1", SourceXForwardedFor},
+		{"client forwarded does not override x-forwarded-for", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"Forwarded":       {"for=1.1.1.1"},
+			"X-Forwarded-For": {"8.8.8.8"},
+		}, "8.8.8.8", SourceXForwardedFor},
+		{"client x-real-ip does not override x-forwarded-for", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"X-Real-Ip":       {"1.1.1.1"},
+			"X-Forwarded-For": {"8.8.8.8"},
+		}, "8.8.8.8", SourceXForwardedFor},
+		{"client forwarded without x-forwarded-for", SourceXForwardedFor, "10.0.0.2:5000", map[string][]string{
+			"Forwarded": {"for=1.1.1.1"},
+		}, "10.0.0.2", SourceRemoteAddr},
+		{"forwarded", SourceForwarded, "10.0.0.2:5000", map[string][]string{
+			"Forwarded":       {`for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711";proto=https`},
+			"X-Forwarded-For": {"1.1.1.1"},
+		}, "2001:db8:cafe::17", SourceForwarded},
+		{"forwarded skips trusted hops", SourceForwarded, "10.0.0.2:5000", map[string][]string{
+			"Forwarded": {`For="8.8.8.8:80", for=10.1.1.1`},
+		}, "8.8.8.8", SourceForwarded},
+		{"x-real-ip", SourceXRealIP, "10.0.0.2:5000", map[string][]string{
+			"X-Real-Ip":       {"8.8.4.4"},
+			"X-Forwarded-For": {"1.1.1.1"},
+		}, "8.8.4.4", SourceXRealIP},
+		{"mapped peer address", SourceXForwardedFor, "[::ffff:10.0.0.2]:5000", map[string][]string{
+			"X-Forwarded-For": {"8.8.8.8"},
+		}, "8.8.8.8", SourceXForwardedFor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewResolver(proxies, tt.header)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+			ip, source, err := resolver.Resolve(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIP, ip.String())
+			assert.Equal(t, tt.source, source)
+		})
+	}
+}
+
+func TestResolve_InvalidHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  Source
+		headers map[string]string
+	}{
+		{"invalid x-forwarded-for", SourceXForwardedFor, map[string]string{"X-Forwarded-For": "8.8.8.8, not-an-ip"}},
+		{"obfuscated forwarded node", SourceForwarded, map[string]string{"Forwarded": "for=_hidden"}},
+		{"unknown forwarded node", SourceForwarded, map[string]string{"Forwarded": "for=unknown"}},
+		{"forwarded without for", SourceForwarded, map[string]string{"Forwarded": "proto=https"}},
+		{"invalid x-real-ip", SourceXRealIP, map[string]string{"X-Real-Ip": "garbage"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewResolver([]string{"10.0.0.0/8"}, tt.header)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.2:5000"
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			_, _, err = resolver.Resolve(req)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrNoClientIP))
+		})
+	}
+}
+
+func TestNewResolver_InvalidProxy(t *testing.T) {
+	_, err := NewResolver([]string{"10.0.0.0/8", "not-a-cidr"}, SourceXForwardedFor)
+	assert.Error(t, err)
+}
+
+func TestNewResolver_InvalidHeader(t *testing.T) {
+	for _, header := range []Source{"", SourceRemoteAddr, "x-client-ip"} {
+		_, err := NewResolver([]string{"10.0.0.0/8"}, header)
+		assert.Error(t, err, header)
+	}
+}
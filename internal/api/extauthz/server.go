@@ -0,0 +1,126 @@
+// Package extauthz implements Envoy's external authorization gRPC service on
+// top of the IP verifier.
+package extauthz
+
+import (
+	"context"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/geoheaders"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/netip"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PolicyExtension is the context extension that selects the named policy,
+// set per route or virtual host in Envoy's ext_authz filter configuration
+const PolicyExtension = "policy_id"
+
+// Server answers Envoy ext_authz Check requests by verifying the downstream
+// remote address against a named policy
+type Server struct {
+	authv3.UnimplementedAuthorizationServer
+
+	ipService     domain.IPVerifierService
+	policyService domain.PolicyService
+}
+
+// NewServer creates an ext_authz server
+func NewServer(ipService domain.IPVerifierService, policyService domain.PolicyService) *Server {
+	return &Server{
+		ipService:     ipService,
+		policyService: policyService,
+	}
+}
+
+// Check allows or denies a request. Allowed requests are forwarded upstream
+// with the geo headers added; denied requests get a 403 with the same
+// headers. Missing policies, unknown addresses and lookup failures are
+// returned as gRPC errors so Envoy's failure_mode_allow setting applies.
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	attrs := req.GetAttributes()
+
+	policyID := strings.TrimSpace(attrs.GetContextExtensions()[PolicyExtension])
+	if policyID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "context extension %s must be provided", PolicyExtension)
+	}
+
+	ip, err := sourceIP(attrs.GetSource())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	policy, err := s.policyService.GetPolicy(ctx, policyID)
+	if err != nil {
+		return nil, status.Error(apperrors.GetGRPCCode(err), apperrors.GetMessage(err))
+	}
+
+	result, err := s.ipService.VerifyIP(ctx, ip.String(), *policy)
+	if err != nil {
+		return nil, status.Error(apperrors.GetGRPCCode(err), apperrors.GetMessage(err))
+	}
+
+	if result.Allowed {
+		return &authv3.CheckResponse{
+			Status: &rpcstatus.Status{Code: int32(codes.OK)},
+			HttpResponse: &authv3.CheckResponse_OkResponse{
+				OkResponse: &authv3.OkHttpResponse{
+					Headers: geoHeaders(result, geoheaders.DecisionAllow),
+				},
+			},
+		}, nil
+	}
+
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{
+			Code:    int32(codes.PermissionDenied),
+			Message: string(result.Reason),
+		},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status:  &typev3.HttpStatus{Code: typev3.StatusCode_Forbidden},
+				Headers: geoHeaders(result, geoheaders.DecisionDeny),
+			},
+		},
+	}, nil
+}
+
+// sourceIP returns the address of the downstream peer. Envoy reports the
+// downstream remote address here, which already accounts for its own
+// use_remote_address and xff_num_trusted_hops settings.
+func sourceIP(peer *authv3.AttributeContext_Peer) (netip.Addr, error) {
+	socket := peer.GetAddress().GetSocketAddress()
+	if socket == nil {
+		return netip.Addr{}, fmt.Errorf("request has no downstream socket address")
+	}
+
+	addr, err := netip.ParseAddr(socket.GetAddress())
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid downstream address %q", socket.GetAddress())
+	}
+	return addr.Unmap().WithZone(""), nil
+}
+
+// geoHeaders builds the X-Geo-Country and X-Geo-Decision headers
+func geoHeaders(result *domain.VerifyResult, decision string) []*corev3.HeaderValueOption {
+	headers := []*corev3.HeaderValueOption{
+		{
+			Header:       &corev3.HeaderValue{Key: geoheaders.Decision, Value: decision},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		},
+	}
+	if result.Country != "" {
+		headers = append(headers, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: geoheaders.Country, Value: result.Country},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+	return headers
+}
@@ -0,0 +1,186 @@
+package extauthz
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockIPVerifierService is a mock implementation of domain.IPVerifierService
+type MockIPVerifierService struct {
+	VerifyIPFunc func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error)
+}
+
+func (m *MockIPVerifierService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+	if m.VerifyIPFunc != nil {
+		return m.VerifyIPFunc(ctx, ip, policy)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) VerifyIPs(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// MockPolicyService is a mock implementation of domain.PolicyService that
+// knows a single policy
+type MockPolicyService struct {
+	Policy domain.Policy
+}
+
+func (m *MockPolicyService) ListPolicies(ctx context.Context) ([]domain.Policy, error) {
+	return []domain.Policy{m.Policy}, nil
+}
+
+func (m *MockPolicyService) GetPolicy(ctx context.Context, id string) (*domain.Policy, error) {
+	if id != m.Policy.ID {
+		return nil, apperrors.NewNotFoundError("Policy not found", nil)
+	}
+	policy := m.Policy
+	return &policy, nil
+}
+
+func (m *MockPolicyService) CreatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	return &policy, nil
+}
+
+func (m *MockPolicyService) UpdatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	return &policy, nil
+}
+
+func (m *MockPolicyService) DeletePolicy(ctx context.Context, id string) error {
+	return nil
+}
+
+func checkRequest(address string, extensions map[string]string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Source: &authv3.AttributeContext_Peer{
+				Address: &corev3.Address{
+					Address: &corev3.Address_SocketAddress{
+						SocketAddress: &corev3.SocketAddress{
+							Address:       address,
+							PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: 40000},
+						},
+					},
+				},
+			},
+			ContextExtensions: extensions,
+		},
+	}
+}
+
+func newTestServer() *Server {
+	ipService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			switch ip {
+			case "8.8.8.8":
+				return &domain.VerifyResult{IP: ip, Country: "US", Allowed: true, Reason: domain.ReasonCountryAllowed}, nil
+			case "77.88.8.8":
+				return &domain.VerifyResult{IP: ip, Country: "RU", Allowed: false, Reason: domain.ReasonNotInAllowlist}, nil
+			default:
+				return nil, apperrors.NewInternalError("Failed to lookup IP", nil)
+			}
+		},
+	}
+	policyService := &MockPolicyService{
+		Policy: domain.Policy{ID: "us-only", AllowedCountries: []string{"US"}},
+	}
+	return NewServer(ipService, policyService)
+}
+
+func headerMap(options []*corev3.HeaderValueOption) map[string]string {
+	headers := make(map[string]string)
+	for _, option := range options {
+		headers[option.GetHeader().GetKey()] = option.GetHeader().GetValue()
+	}
+	return headers
+}
+
+func TestCheck_Allowed(t *testing.T) {
+	resp, err := newTestServer().Check(context.Background(),
+		checkRequest("::ffff:8.8.8.8", map[string]string{PolicyExtension: "us-only"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+	require.NotNil(t, resp.GetOkResponse())
+	assert.Equal(t, map[string]string{
+		"X-Geo-Decision": "allow",
+		"X-Geo-Country":  "US",
+	}, headerMap(resp.GetOkResponse().GetHeaders()))
+}
+
+func TestCheck_Denied(t *testing.T) {
+	resp, err := newTestServer().Check(context.Background(),
+		checkRequest("77.88.8.8", map[string]string{PolicyExtension: "us-only"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(codes.PermissionDenied), resp.GetStatus().GetCode())
+	assert.Equal(t, "not_in_allowlist", resp.GetStatus().GetMessage())
+	require.NotNil(t, resp.GetDeniedResponse())
+	assert.Equal(t, typev3.StatusCode_Forbidden, resp.GetDeniedResponse().GetStatus().GetCode())
+	assert.Equal(t, map[string]string{
+		"X-Geo-Decision": "deny",
+		"X-Geo-Country":  "RU",
+	}, headerMap(resp.GetDeniedResponse().GetHeaders()))
+}
+
+func TestCheck_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *authv3.CheckRequest
+		expectedCode codes.Code
+	}{
+		{
+			name:         "missing policy extension",
+			req:          checkRequest("8.8.8.8", nil),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "unknown policy",
+			req:          checkRequest("8.8.8.8", map[string]string{PolicyExtension: "missing"}),
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "no socket address",
+			req:          &authv3.CheckRequest{Attributes: &authv3.AttributeContext{ContextExtensions: map[string]string{PolicyExtension: "us-only"}}},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "invalid socket address",
+			req:          checkRequest("not-an-ip", map[string]string{PolicyExtension: "us-only"}),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "lookup failure",
+			req:          checkRequest("192.0.2.1", map[string]string{PolicyExtension: "us-only"}),
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newTestServer().Check(context.Background(), tt.req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// Package geoheaders names the headers that carry a verification decision to
+// the proxy in front of a protected service, shared by the forward-auth
+// handler and the Envoy ext_authz server.
+package geoheaders
+
+const (
+	Country  = "X-Geo-Country"
+	Decision = "X-Geo-Decision"
+)
+
+// Values of the X-Geo-Decision header
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
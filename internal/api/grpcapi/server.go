@@ -0,0 +1,226 @@
+// Package grpcapi implements the native gRPC API defined in
+// api/ipverifier/v1 on top of the IP verifier service.
+package grpcapi
+
+import (
+	"context"
+	ipverifierv1 "github.com/KWilliams-dev/ip-verifier/api/ipverifier/v1"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements ipverifierv1.IPVerifierServiceServer
+type Server struct {
+	ipverifierv1.UnimplementedIPVerifierServiceServer
+
+	ipService     domain.IPVerifierService
+	policyService domain.PolicyService
+}
+
+// NewServer creates a gRPC API server
+func NewServer(ipService domain.IPVerifierService, policyService domain.PolicyService) *Server {
+	return &Server{
+		ipService:     ipService,
+		policyService: policyService,
+	}
+}
+
+// Verify checks one IP against a named or inline policy
+func (s *Server) Verify(ctx context.Context, req *ipverifierv1.VerifyRequest) (*ipverifierv1.VerifyResponse, error) {
+	if req.GetIp() == "" {
+		return nil, status.Error(codes.InvalidArgument, "ip is required")
+	}
+
+	policy, err := s.resolvePolicy(ctx, req.GetPolicyId(), req.GetPolicy())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	result, err := s.ipService.VerifyIP(ctx, req.GetIp(), policy)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toVerifyResponse(result), nil
+}
+
+// BatchVerify checks several IPs against the same policy
+func (s *Server) BatchVerify(ctx context.Context, req *ipverifierv1.BatchVerifyRequest) (*ipverifierv1.BatchVerifyResponse, error) {
+	policy, err := s.resolvePolicy(ctx, req.GetPolicyId(), req.GetPolicy())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	results, err := s.ipService.VerifyIPs(ctx, req.GetIps(), policy)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &ipverifierv1.BatchVerifyResponse{
+		Results: make([]*ipverifierv1.BatchVerifyItem, len(results)),
+	}
+	for i, r := range results {
+		item := &ipverifierv1.BatchVerifyItem{Ip: req.GetIps()[i]}
+		if r.Err != nil {
+			item.Error = apperrors.GetMessage(r.Err)
+		} else {
+			item.Result = toVerifyResponse(r.Result)
+		}
+		resp.Results[i] = item
+	}
+	return resp, nil
+}
+
+// Lookup returns the data known about an IP without applying a policy
+func (s *Server) Lookup(ctx context.Context, req *ipverifierv1.LookupRequest) (*ipverifierv1.LookupResponse, error) {
+	if req.GetIp() == "" {
+		return nil, status.Error(codes.InvalidArgument, "ip is required")
+	}
+
+	result, err := s.ipService.LookupIP(ctx, req.GetIp())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toLookupResponse(result), nil
+}
+
+// Health reports whether the GeoIP databases are available
+func (s *Server) Health(ctx context.Context, req *ipverifierv1.HealthRequest) (*ipverifierv1.HealthResponse, error) {
+	if err := s.ipService.HealthCheck(ctx); err != nil {
+		return nil, status.Error(codes.Unavailable, "GeoIP database unavailable")
+	}
+	return &ipverifierv1.HealthResponse{Status: "healthy"}, nil
+}
+
+// resolvePolicy returns the named policy or the inline one. Exactly one of
+// them must be given.
+func (s *Server) resolvePolicy(ctx context.Context, policyID string, inline *ipverifierv1.Policy) (domain.Policy, error) {
+	switch {
+	case policyID != "" && inline != nil:
+		return domain.Policy{}, apperrors.NewValidationError("policy_id cannot be combined with policy", nil)
+	case policyID != "":
+		policy, err := s.policyService.GetPolicy(ctx, policyID)
+		if err != nil {
+			return domain.Policy{}, err
+		}
+		return *policy, nil
+	case inline != nil:
+		return fromPolicy(inline), nil
+	default:
+		return domain.Policy{}, apperrors.NewValidationError("policy_id or policy must be provided", nil)
+	}
+}
+
+// toStatus converts an application error to a gRPC status error
+func toStatus(err error) error {
+	return status.Error(apperrors.GetGRPCCode(err), apperrors.GetMessage(err))
+}
+
+func fromPolicy(p *ipverifierv1.Policy) domain.Policy {
+	policy := domain.Policy{
+		AllowedCountries: p.GetAllowedCountries(),
+		DeniedCountries:  p.GetDeniedCountries(),
+		AllowedASNs:      toUints(p.GetAllowedAsns()),
+		DeniedASNs:       toUints(p.GetDeniedAsns()),
+		AllowedCIDRs:     p.GetAllowedCidrs(),
+		DeniedCIDRs:      p.GetDeniedCidrs(),
+		UnknownAction:    domain.UnknownAction(p.GetUnknownAction()),
+		AllowInternal:    p.GetAllowInternal(),
+		MatchMode:        domain.MatchMode(p.GetMatchMode()),
+	}
+	for _, t := range p.GetDeniedAnonymity() {
+		policy.DeniedAnonymity = append(policy.DeniedAnonymity, domain.AnonymityType(t))
+	}
+	return policy
+}
+
+func toUints(values []uint32) []uint {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]uint, len(values))
+	for i, v := range values {
+		out[i] = uint(v)
+	}
+	return out
+}
+
+func toVerifyResponse(result *domain.VerifyResult) *ipverifierv1.VerifyResponse {
+	resp := &ipverifierv1.VerifyResponse{
+		Ip:                 result.IP,
+		Country:            result.Country,
+		Continent:          result.Continent,
+		RegisteredCountry:  result.RegisteredCountry,
+		RepresentedCountry: result.RepresentedCountry,
+		Subdivisions:       result.Subdivisions,
+		City:               result.City,
+		PostalCode:         result.PostalCode,
+		Coordinates:        toCoordinates(result.Coordinates),
+		Asn:                uint32(result.ASN),
+		AsOrg:              result.ASOrg,
+		IsVpn:              result.Anonymity.IsVPN,
+		IsTor:              result.Anonymity.IsTor,
+		IsHosting:          result.Anonymity.IsHosting,
+		IsPublicProxy:      result.Anonymity.IsPublicProxy,
+		Allowed:            result.Allowed,
+		Reason:             string(result.Reason),
+		RangeType:          string(result.RangeType),
+		MatchedGroup:       result.MatchedGroup,
+	}
+	if result.MatchedRule != nil {
+		resp.MatchedRule = &ipverifierv1.MatchedRule{
+			List:  result.MatchedRule.List,
+			Entry: result.MatchedRule.Entry,
+		}
+	}
+	if !result.DatabaseBuild.IsZero() {
+		resp.DatabaseBuild = timestamppb.New(result.DatabaseBuild)
+	}
+	return resp
+}
+
+func toLookupResponse(result *domain.LookupResult) *ipverifierv1.LookupResponse {
+	resp := &ipverifierv1.LookupResponse{
+		Ip:        result.IP,
+		RangeType: string(result.RangeType),
+	}
+	if l := result.Location; l != nil {
+		resp.Country = l.Country
+		resp.Continent = l.Continent
+		resp.RegisteredCountry = l.RegisteredCountry
+		resp.RepresentedCountry = l.RepresentedCountry
+		resp.Subdivisions = l.Subdivisions
+		resp.City = l.City
+		resp.PostalCode = l.PostalCode
+		resp.Coordinates = toCoordinates(l.Coordinates)
+		if !l.DatabaseBuild.IsZero() {
+			resp.DatabaseBuild = timestamppb.New(l.DatabaseBuild)
+		}
+	}
+	if result.ASN != nil {
+		resp.Asn = uint32(result.ASN.Number)
+		resp.AsOrg = result.ASN.Organization
+	}
+	if a := result.Anonymity; a != nil {
+		resp.IsAnonymous = a.IsAnonymous
+		resp.IsVpn = a.IsVPN
+		resp.IsTor = a.IsTor
+		resp.IsHosting = a.IsHosting
+		resp.IsPublicProxy = a.IsPublicProxy
+	}
+	return resp
+}
+
+func toCoordinates(c *domain.Coordinates) *ipverifierv1.Coordinates {
+	if c == nil {
+		return nil
+	}
+	return &ipverifierv1.Coordinates{
+		Latitude:         c.Latitude,
+		Longitude:        c.Longitude,
+		AccuracyRadiusKm: uint32(c.AccuracyRadius),
+	}
+}
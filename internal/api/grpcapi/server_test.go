@@ -0,0 +1,279 @@
+package grpcapi
+
+import (
+	"context"
+	ipverifierv1 "github.com/KWilliams-dev/ip-verifier/api/ipverifier/v1"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// MockIPVerifierService is a mock implementation of domain.IPVerifierService
+type MockIPVerifierService struct {
+	VerifyIPFunc    func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error)
+	VerifyIPsFunc   func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error)
+	LookupIPFunc    func(ctx context.Context, ip string) (*domain.LookupResult, error)
+	HealthCheckFunc func(ctx context.Context) error
+}
+
+func (m *MockIPVerifierService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+	if m.VerifyIPFunc != nil {
+		return m.VerifyIPFunc(ctx, ip, policy)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) VerifyIPs(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+	if m.VerifyIPsFunc != nil {
+		return m.VerifyIPsFunc(ctx, ips, policy)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	if m.LookupIPFunc != nil {
+		return m.LookupIPFunc(ctx, ip)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) HealthCheck(ctx context.Context) error {
+	if m.HealthCheckFunc != nil {
+		return m.HealthCheckFunc(ctx)
+	}
+	return nil
+}
+
+// MockPolicyService is a mock implementation of domain.PolicyService that
+// knows a single policy
+type MockPolicyService struct {
+	Policy domain.Policy
+}
+
+func (m *MockPolicyService) ListPolicies(ctx context.Context) ([]domain.Policy, error) {
+	return []domain.Policy{m.Policy}, nil
+}
+
+func (m *MockPolicyService) GetPolicy(ctx context.Context, id string) (*domain.Policy, error) {
+	if id != m.Policy.ID {
+		return nil, apperrors.NewNotFoundError("Policy not found", nil)
+	}
+	policy := m.Policy
+	return &policy, nil
+}
+
+func (m *MockPolicyService) CreatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	return &policy, nil
+}
+
+func (m *MockPolicyService) UpdatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	return &policy, nil
+}
+
+func (m *MockPolicyService) DeletePolicy(ctx context.Context, id string) error {
+	return nil
+}
+
+// newTestClient serves the API over an in-memory listener
+func newTestClient(t *testing.T, ipService domain.IPVerifierService) ipverifierv1.IPVerifierServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	ipverifierv1.RegisterIPVerifierServiceServer(server, NewServer(ipService, &MockPolicyService{
+		Policy: domain.Policy{ID: "us-only", AllowedCountries: []string{"US"}},
+	}))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return ipverifierv1.NewIPVerifierServiceClient(conn)
+}
+
+func TestVerify(t *testing.T) {
+	build := time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)
+
+	var gotPolicy domain.Policy
+	client := newTestClient(t, &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotPolicy = policy
+			return &domain.VerifyResult{
+				IP:            ip,
+				Country:       "US",
+				Continent:     "NA",
+				ASN:           15169,
+				ASOrg:         "GOOGLE",
+				Coordinates:   &domain.Coordinates{Latitude: 37.4, Longitude: -122.1, AccuracyRadius: 100},
+				Allowed:       true,
+				Reason:        domain.ReasonCountryAllowed,
+				MatchedRule:   &domain.MatchedRule{List: "allowed_countries", Entry: "US"},
+				DatabaseBuild: build,
+			}, nil
+		},
+	})
+
+	resp, err := client.Verify(context.Background(), &ipverifierv1.VerifyRequest{
+		Ip: "8.8.8.8",
+		Policy: &ipverifierv1.Policy{
+			AllowedCountries: []string{"US"},
+			DeniedAsns:       []uint32{64500},
+			DeniedAnonymity:  []string{"vpn"},
+			MatchMode:        "any",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.Policy{
+		AllowedCountries: []string{"US"},
+		DeniedASNs:       []uint{64500},
+		DeniedAnonymity:  []domain.AnonymityType{domain.AnonymityVPN},
+		MatchMode:        domain.MatchAny,
+	}, gotPolicy)
+
+	assert.Equal(t, "8.8.8.8", resp.GetIp())
+	assert.Equal(t, "US", resp.GetCountry())
+	assert.Equal(t, uint32(15169), resp.GetAsn())
+	assert.Equal(t, uint32(100), resp.GetCoordinates().GetAccuracyRadiusKm())
+	assert.True(t, resp.GetAllowed())
+	assert.Equal(t, "country_allowed", resp.GetReason())
+	assert.Equal(t, "allowed_countries", resp.GetMatchedRule().GetList())
+	assert.Equal(t, build, resp.GetDatabaseBuild().AsTime())
+}
+
+func TestVerify_NamedPolicy(t *testing.T) {
+	var gotPolicy domain.Policy
+	client := newTestClient(t, &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotPolicy = policy
+			return &domain.VerifyResult{IP: ip, Allowed: true, Reason: domain.ReasonCountryAllowed}, nil
+		},
+	})
+
+	_, err := client.Verify(context.Background(), &ipverifierv1.VerifyRequest{Ip: "8.8.8.8", PolicyId: "us-only"})
+	require.NoError(t, err)
+	assert.Equal(t, "us-only", gotPolicy.ID)
+}
+
+func TestVerify_Errors(t *testing.T) {
+	client := newTestClient(t, &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			switch ip {
+			case "not-an-ip":
+				return nil, apperrors.NewValidationError("Invalid IP address", nil)
+			default:
+				return nil, apperrors.NewInternalError("Failed to lookup IP address", nil)
+			}
+		},
+	})
+
+	inline := &ipverifierv1.Policy{AllowedCountries: []string{"US"}}
+
+	tests := []struct {
+		name         string
+		req          *ipverifierv1.VerifyRequest
+		expectedCode codes.Code
+		expectedMsg  string
+	}{
+		{"missing ip", &ipverifierv1.VerifyRequest{Policy: inline}, codes.InvalidArgument, "ip is required"},
+		{"missing policy", &ipverifierv1.VerifyRequest{Ip: "8.8.8.8"}, codes.InvalidArgument, "policy_id or policy must be provided"},
+		{"both policies", &ipverifierv1.VerifyRequest{Ip: "8.8.8.8", PolicyId: "us-only", Policy: inline}, codes.InvalidArgument, "policy_id cannot be combined with policy"},
+		{"unknown policy", &ipverifierv1.VerifyRequest{Ip: "8.8.8.8", PolicyId: "missing"}, codes.NotFound, "Policy not found"},
+		{"invalid ip", &ipverifierv1.VerifyRequest{Ip: "not-an-ip", Policy: inline}, codes.InvalidArgument, "Invalid IP address"},
+		{"lookup failure", &ipverifierv1.VerifyRequest{Ip: "8.8.8.8", Policy: inline}, codes.Internal, "Failed to lookup IP address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Verify(context.Background(), tt.req)
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedMsg, status.Convert(err).Message())
+		})
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	client := newTestClient(t, &MockIPVerifierService{
+		VerifyIPsFunc: func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+			return []domain.BatchResult{
+				{Result: &domain.VerifyResult{IP: ips[0], Country: "US", Allowed: true, Reason: domain.ReasonCountryAllowed}},
+				{Err: apperrors.NewValidationError("Invalid IP address", nil)},
+			}, nil
+		},
+	})
+
+	resp, err := client.BatchVerify(context.Background(), &ipverifierv1.BatchVerifyRequest{
+		Ips:      []string{"8.8.8.8", "not-an-ip"},
+		PolicyId: "us-only",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 2)
+
+	assert.Equal(t, "8.8.8.8", resp.GetResults()[0].GetIp())
+	assert.True(t, resp.GetResults()[0].GetResult().GetAllowed())
+	assert.Empty(t, resp.GetResults()[0].GetError())
+
+	assert.Equal(t, "not-an-ip", resp.GetResults()[1].GetIp())
+	assert.Nil(t, resp.GetResults()[1].GetResult())
+	assert.Equal(t, "Invalid IP address", resp.GetResults()[1].GetError())
+}
+
+func TestLookup(t *testing.T) {
+	client := newTestClient(t, &MockIPVerifierService{
+		LookupIPFunc: func(ctx context.Context, ip string) (*domain.LookupResult, error) {
+			return &domain.LookupResult{
+				IP:        ip,
+				Location:  &domain.Location{Country: "NL", Continent: "EU"},
+				ASN:       &domain.ASN{Number: 64500, Organization: "HOSTING"},
+				Anonymity: &domain.Anonymity{IsAnonymous: true, IsVPN: true},
+			}, nil
+		},
+	})
+
+	resp, err := client.Lookup(context.Background(), &ipverifierv1.LookupRequest{Ip: "5.5.5.5"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "NL", resp.GetCountry())
+	assert.Equal(t, "EU", resp.GetContinent())
+	assert.Equal(t, uint32(64500), resp.GetAsn())
+	assert.Equal(t, "HOSTING", resp.GetAsOrg())
+	assert.True(t, resp.GetIsAnonymous())
+	assert.True(t, resp.GetIsVpn())
+	assert.Nil(t, resp.GetDatabaseBuild())
+}
+
+func TestHealth(t *testing.T) {
+	healthy := true
+	client := newTestClient(t, &MockIPVerifierService{
+		HealthCheckFunc: func(ctx context.Context) error {
+			if healthy {
+				return nil
+			}
+			return apperrors.NewInternalError("database closed", nil)
+		},
+	})
+
+	resp, err := client.Health(context.Background(), &ipverifierv1.HealthRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", resp.GetStatus())
+
+	healthy = false
+	_, err = client.Health(context.Background(), &ipverifierv1.HealthRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/middleware"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClientIPVerifyRequest struct {
+	PolicyID         string                 `json:"policy_id"`
+	AllowedCountries []string               `json:"allowed_countries"`
+	DeniedCountries  []string               `json:"denied_countries"`
+	AllowedASNs      []uint                 `json:"allowed_asns"`
+	DeniedASNs       []uint                 `json:"denied_asns"`
+	AllowedCIDRs     []string               `json:"allowed_cidrs"`
+	DeniedCIDRs      []string               `json:"denied_cidrs"`
+	DeniedAnonymity  []domain.AnonymityType `json:"denied_anonymity"`
+	UnknownAction    string                 `json:"unknown_action"`
+	AllowInternal    bool                   `json:"allow_internal"`
+	MatchMode        string                 `json:"match_mode"`
+}
+
+type ClientIPVerifyResponse struct {
+	VerifyResponse
+	ClientIPSource string `json:"client_ip_source"`
+}
+
+// VerifyClientIP creates a handler that verifies the caller's own IP. The IP
+// is taken from the connection or, behind trusted proxies, from the
+// forwarding headers; the source used is reported in the response.
+func VerifyClientIP(resolver *clientip.Resolver, ipService domain.IPVerifierService, policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var verifyReq ClientIPVerifyRequest
+
+		if err := c.ShouldBindJSON(&verifyReq); err != nil {
+			writeError(c, apperrors.NewValidationError(err.Error(), err))
+			return
+		}
+
+		ip, source, err := resolver.Resolve(c.Request)
+		if err != nil {
+			writeError(c, apperrors.NewValidationError(err.Error(), err))
+			return
+		}
+
+		policy, err := resolvePolicy(c.Request.Context(), policyService, verifyReq.PolicyID, domain.Policy{
+			AllowedCountries: verifyReq.AllowedCountries,
+			DeniedCountries:  verifyReq.DeniedCountries,
+			AllowedASNs:      verifyReq.AllowedASNs,
+			DeniedASNs:       verifyReq.DeniedASNs,
+			AllowedCIDRs:     verifyReq.AllowedCIDRs,
+			DeniedCIDRs:      verifyReq.DeniedCIDRs,
+			DeniedAnonymity:  verifyReq.DeniedAnonymity,
+			UnknownAction:    domain.UnknownAction(verifyReq.UnknownAction),
+			AllowInternal:    verifyReq.AllowInternal,
+			MatchMode:        domain.MatchMode(verifyReq.MatchMode),
+		})
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		result, err := ipService.VerifyIP(c.Request.Context(), ip.String(), policy)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		middleware.SetVerification(c, result)
+
+		c.JSON(http.StatusOK, ClientIPVerifyResponse{
+			VerifyResponse: toVerifyResponse(result),
+			ClientIPSource: string(source),
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/geoheaders"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/middleware"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyHeader names the policy to apply when the policy_id query parameter
+// is absent
+const PolicyHeader = "X-Geo-Policy"
+
+// ForwardAuth creates a handler for nginx auth_request and Traefik
+// forwardAuth. It verifies the client IP of the original request against a
+// named policy and answers with an empty 200 or 403; the country and
+// decision are returned in the X-Geo-Country and X-Geo-Decision headers.
+// Requests that cannot be verified (no or an unknown policy, a client IP that
+// cannot be determined or a failed lookup) are denied as well, since nginx
+// turns any other status into a 500 for the protected service; the cause is
+// logged instead.
+func ForwardAuth(resolver *clientip.Resolver, ipService domain.IPVerifierService, policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID := strings.TrimSpace(c.Query("policy_id"))
+		if policyID == "" {
+			policyID = strings.TrimSpace(c.GetHeader(PolicyHeader))
+		}
+		if policyID == "" {
+			denyForwardAuth(c, apperrors.NewValidationError(
+				"policy_id query parameter or "+PolicyHeader+" header must be provided", nil))
+			return
+		}
+
+		policy, err := policyService.GetPolicy(c.Request.Context(), policyID)
+		if err != nil {
+			denyForwardAuth(c, err)
+			return
+		}
+
+		ip, _, err := resolver.Resolve(c.Request)
+		if err != nil {
+			denyForwardAuth(c, apperrors.NewValidationError(err.Error(), err))
+			return
+		}
+
+		result, err := ipService.VerifyIP(c.Request.Context(), ip.String(), *policy)
+		if err != nil {
+			denyForwardAuth(c, err)
+			return
+		}
+		middleware.SetVerification(c, result)
+
+		if result.Country != "" {
+			c.Header(geoheaders.Country, result.Country)
+		}
+		if result.Allowed {
+			c.Header(geoheaders.Decision, geoheaders.DecisionAllow)
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Header(geoheaders.Decision, geoheaders.DecisionDeny)
+		c.Status(http.StatusForbidden)
+	}
+}
+
+// denyForwardAuth answers a request that could not be verified with a bare
+// 403 and logs why
+func denyForwardAuth(c *gin.Context, err error) {
+	level := slog.LevelWarn
+	if apperrors.GetHTTPStatus(err) >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(c.Request.Context(), level, "Forward auth request denied", "error", err)
+
+	c.Header(geoheaders.Decision, geoheaders.DecisionDeny)
+	c.Status(http.StatusForbidden)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/geoheaders"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			switch ip {
+			case "8.8.8.8":
+				return &domain.VerifyResult{IP: ip, Country: "US", Allowed: true, Reason: domain.ReasonCountryAllowed}, nil
+			case "77.88.8.8":
+				return &domain.VerifyResult{IP: ip, Country: "RU", Allowed: false, Reason: domain.ReasonNotInAllowlist}, nil
+			case "198.51.100.1":
+				return nil, apperrors.NewInternalError("Failed to lookup IP address", nil)
+			default:
+				return &domain.VerifyResult{IP: ip, Allowed: false, Reason: domain.ReasonUnknownLocation}, nil
+			}
+		},
+	}
+	policyService := &MockPolicyService{
+		GetPolicyFunc: func(ctx context.Context, id string) (*domain.Policy, error) {
+			return &domain.Policy{ID: id, AllowedCountries: []string{"US"}}, nil
+		},
+	}
+
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, clientip.SourceXForwardedFor)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/auth", ForwardAuth(resolver, mockService, policyService))
+
+	tests := []struct {
+		name             string
+		target           string
+		headers          map[string]string
+		expectedStatus   int
+		expectedCountry  string
+		expectedDecision string
+	}{
+		{
+			name:             "allowed via query parameter",
+			target:           "/auth?policy_id=us-only",
+			headers:          map[string]string{"X-Forwarded-For": "8.8.8.8"},
+			expectedStatus:   http.StatusOK,
+			expectedCountry:  "US",
+			expectedDecision: geoheaders.DecisionAllow,
+		},
+		{
+			name:             "denied via policy header",
+			target:           "/auth",
+			headers:          map[string]string{"X-Forwarded-For": "77.88.8.8", PolicyHeader: "us-only"},
+			expectedStatus:   http.StatusForbidden,
+			expectedCountry:  "RU",
+			expectedDecision: geoheaders.DecisionDeny,
+		},
+		{
+			name:             "unknown country",
+			target:           "/auth?policy_id=us-only",
+			headers:          map[string]string{"X-Forwarded-For": "192.0.2.1"},
+			expectedStatus:   http.StatusForbidden,
+			expectedDecision: geoheaders.DecisionDeny,
+		},
+		{
+			name:             "missing policy",
+			target:           "/auth",
+			headers:          map[string]string{"X-Forwarded-For": "8.8.8.8"},
+			expectedStatus:   http.StatusForbidden,
+			expectedDecision: geoheaders.DecisionDeny,
+		},
+		{
+			name:             "lookup failure",
+			target:           "/auth?policy_id=us-only",
+			headers:          map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expectedStatus:   http.StatusForbidden,
+			expectedDecision: geoheaders.DecisionDeny,
+		},
+		{
+			name:             "invalid forwarding header",
+			target:           "/auth?policy_id=us-only",
+			headers:          map[string]string{"X-Forwarded-For": "garbage"},
+			expectedStatus:   http.StatusForbidden,
+			expectedDecision: geoheaders.DecisionDeny,
+		},
+		{
+			name:   "injected forwarded header ignored",
+			target: "/auth?policy_id=us-only",
+			headers: map[string]string{
+				"Forwarded":       "for=8.8.8.8",
+				"X-Real-IP":       "8.8.8.8",
+				"X-Forwarded-For": "77.88.8.8",
+			},
+			expectedStatus:   http.StatusForbidden,
+			expectedCountry:  "RU",
+			expectedDecision: geoheaders.DecisionDeny,
+		},
+		{
+			name:   "injected malformed forwarded header ignored",
+			target: "/auth?policy_id=us-only",
+			headers: map[string]string{
+				"Forwarded":       "garbage",
+				"X-Forwarded-For": "8.8.8.8",
+			},
+			expectedStatus:   http.StatusOK,
+			expectedCountry:  "US",
+			expectedDecision: geoheaders.DecisionAllow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.RemoteAddr = "10.0.0.2:5000"
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedCountry, w.Header().Get(geoheaders.Country))
+			assert.Equal(t, tt.expectedDecision, w.Header().Get(geoheaders.Decision))
+			if tt.expectedDecision != "" {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestForwardAuth_PolicyNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resolver, err := clientip.NewResolver(nil, clientip.SourceXForwardedFor)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/auth", ForwardAuth(resolver, &MockIPVerifierService{}, &MockPolicyService{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/auth?policy_id=missing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, geoheaders.DecisionDeny, w.Header().Get(geoheaders.Decision))
+	assert.Empty(t, w.Body.String())
+}
@@ -1,7 +1,8 @@
 package handler
 
 import (
-	"ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +18,7 @@ func HealthCheck(ipService domain.IPVerifierService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		err := ipService.HealthCheck(c.Request.Context())
 		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Health check failed", "error", err)
 			c.JSON(http.StatusServiceUnavailable, HealthResponse{
 				Status:  "unhealthy",
 				Message: "GeoIP database unavailable",
@@ -1,46 +1,264 @@
 package handler
 
 import (
-	"ip-verifier/internal/domain"
-	apperrors "ip-verifier/internal/errors"
+	"errors"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/middleware"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = otel.Tracer("github.com/KWilliams-dev/ip-verifier/internal/api/handler")
+
 type VerifyRequest struct {
-	IP               string   `json:"ip" binding:"required"`
-	AllowedCountries []string `json:"allowed_countries" binding:"required"`
+	IP               string                 `json:"ip" binding:"required"`
+	PolicyID         string                 `json:"policy_id"`
+	AllowedCountries []string               `json:"allowed_countries"`
+	DeniedCountries  []string               `json:"denied_countries"`
+	AllowedASNs      []uint                 `json:"allowed_asns"`
+	DeniedASNs       []uint                 `json:"denied_asns"`
+	AllowedCIDRs     []string               `json:"allowed_cidrs"`
+	DeniedCIDRs      []string               `json:"denied_cidrs"`
+	DeniedAnonymity  []domain.AnonymityType `json:"denied_anonymity"`
+	UnknownAction    string                 `json:"unknown_action"`
+	AllowInternal    bool                   `json:"allow_internal"`
+	MatchMode        string                 `json:"match_mode"`
 }
 
 type VerifyResponse struct {
-	IP      string `json:"ip"`
-	Country string `json:"country,omitempty"`
-	Allowed bool   `json:"allowed"`
+	IP                 string               `json:"ip"`
+	Country            string               `json:"country,omitempty"`
+	Continent          string               `json:"continent,omitempty"`
+	RegisteredCountry  string               `json:"registered_country,omitempty"`
+	RepresentedCountry string               `json:"represented_country,omitempty"`
+	Subdivisions       []string             `json:"subdivisions,omitempty"`
+	City               string               `json:"city,omitempty"`
+	PostalCode         string               `json:"postal_code,omitempty"`
+	Coordinates        *CoordinatesResponse `json:"coordinates,omitempty"`
+	ASN                uint                 `json:"asn,omitempty"`
+	ASOrg              string               `json:"as_org,omitempty"`
+	IsVPN              bool                 `json:"is_vpn,omitempty"`
+	IsTor              bool                 `json:"is_tor,omitempty"`
+	IsHosting          bool                 `json:"is_hosting,omitempty"`
+	IsPublicProxy      bool                 `json:"is_public_proxy,omitempty"`
+	Allowed            bool                 `json:"allowed"`
+	Reason             string               `json:"reason"`
+	RangeType          string               `json:"range_type,omitempty"`
+	MatchedGroup       string               `json:"matched_group,omitempty"`
+	MatchedRule        *MatchedRuleResponse `json:"matched_rule,omitempty"`
+	DatabaseBuild      string               `json:"database_build,omitempty"`
+}
+
+type CoordinatesResponse struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	AccuracyRadius uint16  `json:"accuracy_radius_km,omitempty"`
 }
 
-func VerifyIP(ipService domain.IPVerifierService) gin.HandlerFunc {
+type MatchedRuleResponse struct {
+	List  string `json:"list"`
+	Entry string `json:"entry"`
+}
+
+func VerifyIP(ipService domain.IPVerifierService, policyService domain.PolicyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx, span := tracer.Start(c.Request.Context(), "handler.VerifyIP")
+		var err error
+		defer func() { tracing.EndSpan(span, err) }()
+
 		var verifyReq VerifyRequest
 
-		if err := c.ShouldBindJSON(&verifyReq); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err = c.ShouldBindJSON(&verifyReq); err != nil {
+			err = apperrors.NewValidationError(err.Error(), err)
+			writeError(c, err)
+			return
+		}
+
+		if verifyReq.PolicyID != "" {
+			span.SetAttributes(attribute.String("verify.policy_id", verifyReq.PolicyID))
+		}
+
+		policy, err := resolvePolicy(ctx, policyService, verifyReq.PolicyID, domain.Policy{
+			AllowedCountries: verifyReq.AllowedCountries,
+			DeniedCountries:  verifyReq.DeniedCountries,
+			AllowedASNs:      verifyReq.AllowedASNs,
+			DeniedASNs:       verifyReq.DeniedASNs,
+			AllowedCIDRs:     verifyReq.AllowedCIDRs,
+			DeniedCIDRs:      verifyReq.DeniedCIDRs,
+			DeniedAnonymity:  verifyReq.DeniedAnonymity,
+			UnknownAction:    domain.UnknownAction(verifyReq.UnknownAction),
+			AllowInternal:    verifyReq.AllowInternal,
+			MatchMode:        domain.MatchMode(verifyReq.MatchMode),
+		})
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		result, err := ipService.VerifyIP(ctx, verifyReq.IP, policy)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		middleware.SetVerification(c, result)
+
+		c.JSON(http.StatusOK, toVerifyResponse(result))
+	}
+}
+
+type BatchVerifyRequest struct {
+	IPs              []string               `json:"ips" binding:"required"`
+	PolicyID         string                 `json:"policy_id"`
+	AllowedCountries []string               `json:"allowed_countries"`
+	DeniedCountries  []string               `json:"denied_countries"`
+	AllowedASNs      []uint                 `json:"allowed_asns"`
+	DeniedASNs       []uint                 `json:"denied_asns"`
+	AllowedCIDRs     []string               `json:"allowed_cidrs"`
+	DeniedCIDRs      []string               `json:"denied_cidrs"`
+	DeniedAnonymity  []domain.AnonymityType `json:"denied_anonymity"`
+	UnknownAction    string                 `json:"unknown_action"`
+	AllowInternal    bool                   `json:"allow_internal"`
+	MatchMode        string                 `json:"match_mode"`
+}
+
+// BatchVerifyItem is the result for one IP of a batch: the VerifyResponse
+// fields, or only ip and error when the IP could not be verified
+type BatchVerifyItem struct {
+	IP string `json:"ip"`
+	*VerifyResponse
+	Error string `json:"error,omitempty"`
+}
+
+type BatchVerifyResponse struct {
+	Results []BatchVerifyItem `json:"results"`
+}
+
+const (
+	// batchBytesPerIP bounds the JSON of one batch IP: the longest IPv6 text
+	// form plus quotes, separator and whitespace
+	batchBytesPerIP = 64
+	// batchPolicyBytes leaves room for an inline policy next to the IPs
+	batchPolicyBytes = 1 << 20
+)
+
+// VerifyIPBatch creates a handler verifying up to maxBatchSize IPs against
+// one policy. The request body is capped to what such a batch needs, so an
+// oversized batch is rejected without reading all of it into memory.
+func VerifyIPBatch(ipService domain.IPVerifierService, policyService domain.PolicyService, maxBatchSize int) gin.HandlerFunc {
+	maxBodyBytes := int64(maxBatchSize)*batchBytesPerIP + batchPolicyBytes
+
+	return func(c *gin.Context) {
+		var batchReq BatchVerifyRequest
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes)
+		if err := c.ShouldBindJSON(&batchReq); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(c, apperrors.NewValidationError(fmt.Sprintf(
+					"request body cannot exceed %d bytes for a batch of up to %d IPs", maxBodyBytes, maxBatchSize), err))
+				return
+			}
+			writeError(c, apperrors.NewValidationError(err.Error(), err))
 			return
 		}
 
-		result, err := ipService.VerifyIP(c.Request.Context(), verifyReq.IP, verifyReq.AllowedCountries)
+		policy, err := resolvePolicy(c.Request.Context(), policyService, batchReq.PolicyID, domain.Policy{
+			AllowedCountries: batchReq.AllowedCountries,
+			DeniedCountries:  batchReq.DeniedCountries,
+			AllowedASNs:      batchReq.AllowedASNs,
+			DeniedASNs:       batchReq.DeniedASNs,
+			AllowedCIDRs:     batchReq.AllowedCIDRs,
+			DeniedCIDRs:      batchReq.DeniedCIDRs,
+			DeniedAnonymity:  batchReq.DeniedAnonymity,
+			UnknownAction:    domain.UnknownAction(batchReq.UnknownAction),
+			AllowInternal:    batchReq.AllowInternal,
+			MatchMode:        domain.MatchMode(batchReq.MatchMode),
+		})
 		if err != nil {
-			status := apperrors.GetHTTPStatus(err)
-			message := apperrors.GetMessage(err)
-			c.JSON(status, gin.H{"error": message})
+			writeError(c, err)
 			return
 		}
 
-		resp := VerifyResponse{
-			IP:      result.IP,
-			Country: result.Country,
-			Allowed: result.Allowed,
+		results, err := ipService.VerifyIPs(c.Request.Context(), batchReq.IPs, policy)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp := BatchVerifyResponse{
+			Results: make([]BatchVerifyItem, len(results)),
+		}
+		for i, item := range results {
+			resp.Results[i] = BatchVerifyItem{IP: batchReq.IPs[i]}
+			if item.Err != nil {
+				resp.Results[i].Error = apperrors.GetMessage(item.Err)
+				continue
+			}
+			verifyResp := toVerifyResponse(item.Result)
+			resp.Results[i].VerifyResponse = &verifyResp
 		}
 		c.JSON(http.StatusOK, resp)
 	}
 }
+
+func toCoordinatesResponse(coordinates *domain.Coordinates) *CoordinatesResponse {
+	if coordinates == nil {
+		return nil
+	}
+	return &CoordinatesResponse{
+		Latitude:       coordinates.Latitude,
+		Longitude:      coordinates.Longitude,
+		AccuracyRadius: coordinates.AccuracyRadius,
+	}
+}
+
+func toMatchedRuleResponse(rule *domain.MatchedRule) *MatchedRuleResponse {
+	if rule == nil {
+		return nil
+	}
+	return &MatchedRuleResponse{
+		List:  rule.List,
+		Entry: rule.Entry,
+	}
+}
+
+// formatBuild renders a database build time as RFC 3339, or "" when unset
+func formatBuild(build time.Time) string {
+	if build.IsZero() {
+		return ""
+	}
+	return build.UTC().Format(time.RFC3339)
+}
+
+func toVerifyResponse(result *domain.VerifyResult) VerifyResponse {
+	return VerifyResponse{
+		IP:                 result.IP,
+		Country:            result.Country,
+		Continent:          result.Continent,
+		RegisteredCountry:  result.RegisteredCountry,
+		RepresentedCountry: result.RepresentedCountry,
+		Subdivisions:       result.Subdivisions,
+		City:               result.City,
+		PostalCode:         result.PostalCode,
+		Coordinates:        toCoordinatesResponse(result.Coordinates),
+		ASN:                result.ASN,
+		ASOrg:              result.ASOrg,
+		IsVPN:              result.Anonymity.IsVPN,
+		IsTor:              result.Anonymity.IsTor,
+		IsHosting:          result.Anonymity.IsHosting,
+		IsPublicProxy:      result.Anonymity.IsPublicProxy,
+		Allowed:            result.Allowed,
+		Reason:             string(result.Reason),
+		RangeType:          string(result.RangeType),
+		MatchedGroup:       result.MatchedGroup,
+		MatchedRule:        toMatchedRuleResponse(result.MatchedRule),
+		DatabaseBuild:      formatBuild(result.DatabaseBuild),
+	}
+}
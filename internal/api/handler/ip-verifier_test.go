@@ -4,25 +4,52 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/middleware"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing/tracingtest"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MockIPVerifierService is a mock implementation of domain.IPVerifierService
 type MockIPVerifierService struct {
-	VerifyIPFunc    func(ctx context.Context, ip string, allowedCountries []string) (*domain.VerifyResult, error)
+	VerifyIPFunc    func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error)
+	VerifyIPsFunc   func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error)
+	LookupIPFunc    func(ctx context.Context, ip string) (*domain.LookupResult, error)
 	HealthCheckFunc func(ctx context.Context) error
 }
 
-func (m *MockIPVerifierService) VerifyIP(ctx context.Context, ip string, allowedCountries []string) (*domain.VerifyResult, error) {
+func (m *MockIPVerifierService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
 	if m.VerifyIPFunc != nil {
-		return m.VerifyIPFunc(ctx, ip, allowedCountries)
+		return m.VerifyIPFunc(ctx, ip, policy)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) VerifyIPs(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+	if m.VerifyIPsFunc != nil {
+		return m.VerifyIPsFunc(ctx, ips, policy)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	if m.LookupIPFunc != nil {
+		return m.LookupIPFunc(ctx, ip)
 	}
 	return nil, nil
 }
@@ -38,7 +65,7 @@ func TestVerifyIP_Success_Allowed(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockIPVerifierService{
-		VerifyIPFunc: func(ctx context.Context, ip string, allowedCountries []string) (*domain.VerifyResult, error) {
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
 			return &domain.VerifyResult{
 				IP:      ip,
 				Country: "US",
@@ -48,7 +75,7 @@ func TestVerifyIP_Success_Allowed(t *testing.T) {
 	}
 
 	router := gin.Default()
-	router.POST("/verify", VerifyIP(mockService))
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
 
 	reqBody := VerifyRequest{
 		IP:               "8.8.8.8",
@@ -75,7 +102,7 @@ func TestVerifyIP_Success_NotAllowed(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockIPVerifierService{
-		VerifyIPFunc: func(ctx context.Context, ip string, allowedCountries []string) (*domain.VerifyResult, error) {
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
 			return &domain.VerifyResult{
 				IP:      ip,
 				Country: "CN",
@@ -85,7 +112,7 @@ func TestVerifyIP_Success_NotAllowed(t *testing.T) {
 	}
 
 	router := gin.Default()
-	router.POST("/verify", VerifyIP(mockService))
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
 
 	reqBody := VerifyRequest{
 		IP:               "1.2.3.4",
@@ -113,7 +140,7 @@ func TestVerifyIP_InvalidJSON(t *testing.T) {
 
 	mockService := &MockIPVerifierService{}
 	router := gin.Default()
-	router.POST("/verify", VerifyIP(mockService))
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
 
 	req, _ := http.NewRequest("POST", "/verify", bytes.NewBufferString("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
@@ -128,11 +155,11 @@ func TestVerifyIP_MissingRequiredFields(t *testing.T) {
 
 	mockService := &MockIPVerifierService{}
 	router := gin.Default()
-	router.POST("/verify", VerifyIP(mockService))
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
 
 	reqBody := VerifyRequest{
 		IP: "8.8.8.8",
-		// Missing AllowedCountries
+		// Missing AllowedCountries and DeniedCountries
 	}
 	body, _ := json.Marshal(reqBody)
 
@@ -148,13 +175,13 @@ func TestVerifyIP_RepoError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockService := &MockIPVerifierService{
-		VerifyIPFunc: func(ctx context.Context, ip string, allowedCountries []string) (*domain.VerifyResult, error) {
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
 			return nil, fmt.Errorf("invalid IP address: %s", ip)
 		},
 	}
 
 	router := gin.Default()
-	router.POST("/verify", VerifyIP(mockService))
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
 
 	reqBody := VerifyRequest{
 		IP:               "invalid-ip",
@@ -170,3 +197,392 @@ func TestVerifyIP_RepoError(t *testing.T) {
 	// Generic errors map to 500
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestVerifyIP_ErrorRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(requestid.NewLogHandler(slog.NewJSONHandler(&logs, nil))))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			if ip == "1.1.1.1" {
+				return nil, apperrors.NewInternalError("Failed to lookup IP address", errors.New("corrupt database"))
+			}
+			return nil, apperrors.NewValidationError("Invalid IP address", nil)
+		},
+	}
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
+
+	tests := []struct {
+		name       string
+		body       string
+		status     int
+		message    string
+		logsRecord bool
+	}{
+		{"invalid JSON", `{`, http.StatusBadRequest, "unexpected EOF", false},
+		{"validation error", `{"ip":"nope","allowed_countries":["US"]}`, http.StatusBadRequest, "Invalid IP address", false},
+		{"internal error", `{"ip":"1.1.1.1","allowed_countries":["US"]}`, http.StatusInternalServerError, "Failed to lookup IP address", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req, _ := http.NewRequest("POST", "/verify", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Request-ID", "req-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "req-123", w.Header().Get("X-Request-ID"))
+			assert.JSONEq(t, `{"error":"`+tt.message+`","request_id":"req-123"}`, w.Body.String())
+
+			if !tt.logsRecord {
+				assert.Empty(t, logs.String())
+				return
+			}
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+			assert.Equal(t, "req-123", record["request_id"])
+			assert.Equal(t, "Failed to lookup IP address: corrupt database", record["error"])
+		})
+	}
+}
+
+func TestVerifyIPBatch_PerItemErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockIPVerifierService{
+		VerifyIPsFunc: func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+			return []domain.BatchResult{
+				{Result: &domain.VerifyResult{IP: ips[0], Country: "US", Allowed: true, Reason: domain.ReasonCountryAllowed}},
+				{Err: apperrors.NewValidationError("Invalid IP address", nil)},
+			}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify/batch", VerifyIPBatch(mockService, &MockPolicyService{}, 2))
+
+	reqBody := BatchVerifyRequest{
+		IPs:              []string{"8.8.8.8", "not-an-ip"},
+		AllowedCountries: []string{"US"},
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/verify/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// Verified items carry the same fields as a single verification
+	assert.JSONEq(t, `{"results":[
+		{"ip":"8.8.8.8","country":"US","allowed":true,"reason":"country_allowed"},
+		{"ip":"not-an-ip","error":"Invalid IP address"}
+	]}`, w.Body.String())
+}
+
+func TestVerifyIPBatch_TooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockIPVerifierService{
+		VerifyIPsFunc: func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+			return nil, apperrors.NewValidationError("batch cannot contain more than 1 IPs", nil)
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify/batch", VerifyIPBatch(mockService, &MockPolicyService{}, 2))
+
+	reqBody := BatchVerifyRequest{
+		IPs:              []string{"8.8.8.8", "1.1.1.1"},
+		AllowedCountries: []string{"US"},
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req, _ := http.NewRequest("POST", "/verify/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"batch cannot contain more than 1 IPs"}`, w.Body.String())
+}
+
+func TestVerifyIPBatch_BodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	called := false
+	mockService := &MockIPVerifierService{
+		VerifyIPsFunc: func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+			called = true
+			return nil, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify/batch", VerifyIPBatch(mockService, &MockPolicyService{}, 2))
+
+	ips := make([]string, 100000)
+	for i := range ips {
+		ips[i] = "2001:db8::1"
+	}
+	body, _ := json.Marshal(BatchVerifyRequest{IPs: ips, AllowedCountries: []string{"US"}})
+
+	req, _ := http.NewRequest("POST", "/verify/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "request body cannot exceed 1048704 bytes for a batch of up to 2 IPs")
+	assert.False(t, called)
+}
+
+func TestVerifyIP_DeniedCountriesOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotPolicy domain.Policy
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotPolicy = policy
+			return &domain.VerifyResult{
+				IP:      ip,
+				Country: "US",
+				Allowed: true,
+			}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
+
+	body := []byte(`{"ip":"8.8.8.8","denied_countries":["KP","IR"]}`)
+
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, gotPolicy.AllowedCountries)
+	assert.Equal(t, []string{"KP", "IR"}, gotPolicy.DeniedCountries)
+}
+
+func TestVerifyIP_ASNLists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotPolicy domain.Policy
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotPolicy = policy
+			return &domain.VerifyResult{
+				IP:      ip,
+				Country: "US",
+				ASN:     15169,
+				ASOrg:   "GOOGLE",
+				Allowed: true,
+				Reason:  domain.ReasonASNAllowed,
+			}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
+
+	body := []byte(`{"ip":"8.8.8.8","allowed_asns":[15169],"denied_asns":[64500]}`)
+
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []uint{15169}, gotPolicy.AllowedASNs)
+	assert.Equal(t, []uint{64500}, gotPolicy.DeniedASNs)
+	assert.JSONEq(t, `{"ip":"8.8.8.8","country":"US","asn":15169,"as_org":"GOOGLE","allowed":true,"reason":"asn_allowed"}`, w.Body.String())
+}
+
+func TestVerifyIP_Explanation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			return &domain.VerifyResult{
+				IP:            ip,
+				Country:       "DE",
+				Continent:     "EU",
+				Allowed:       false,
+				Reason:        domain.ReasonCountryDenied,
+				MatchedGroup:  "group:EU",
+				MatchedRule:   &domain.MatchedRule{List: "denied_countries", Entry: "group:EU"},
+				DatabaseBuild: time.Date(2024, 5, 7, 12, 0, 0, 0, time.UTC),
+			}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
+
+	body := []byte(`{"ip":"2.2.2.2","denied_countries":["group:EU"]}`)
+
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"ip": "2.2.2.2",
+		"country": "DE",
+		"continent": "EU",
+		"allowed": false,
+		"reason": "country_denied",
+		"matched_group": "group:EU",
+		"matched_rule": {"list": "denied_countries", "entry": "group:EU"},
+		"database_build": "2024-05-07T12:00:00Z"
+	}`, w.Body.String())
+}
+
+func TestVerifyIP_Tracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		statusCode codes.Code
+	}{
+		{"success", nil, codes.Unset},
+		{"validation error", apperrors.NewValidationError("Invalid IP address", nil), codes.Unset},
+		{"internal error", apperrors.NewInternalError("Failed to lookup IP address", nil), codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serviceParent trace.SpanContext
+			mockService := &MockIPVerifierService{
+				VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+					serviceParent = trace.SpanContextFromContext(ctx)
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.VerifyResult{IP: ip, Country: "US", Allowed: true}, nil
+				},
+			}
+
+			router := gin.New()
+			router.POST("/verify", VerifyIP(mockService, &MockPolicyService{}))
+
+			ctx, parent := tracingtest.NewParent(t.Context())
+			req, _ := http.NewRequestWithContext(ctx, "POST", "/verify", bytes.NewBufferString(`{"ip":"8.8.8.8","allowed_countries":["US"]}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := tracingtest.Spans(parent.TraceID())
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, "handler.VerifyIP", span.Name())
+			assert.Equal(t, parent.SpanID(), span.Parent().SpanID())
+			assert.Equal(t, span.SpanContext().SpanID(), serviceParent.SpanID(), "service should be called with the handler span")
+			assert.Equal(t, tt.statusCode, span.Status().Code)
+			if tt.err != nil {
+				require.Len(t, span.Events(), 1)
+				assert.Equal(t, "exception", span.Events()[0].Name)
+			}
+		})
+	}
+}
+
+func TestVerifyIP_TracingBindError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/verify", VerifyIP(&MockIPVerifierService{}, &MockPolicyService{}))
+
+	ctx, parent := tracingtest.NewParent(t.Context())
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/verify", bytes.NewBufferString(`{"ip":`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	spans := tracingtest.Spans(parent.TraceID())
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	// The validation error is recorded once, by tracing.EndSpan
+	require.Len(t, span.Events(), 1)
+	var errorType string
+	for _, attr := range span.Events()[0].Attributes {
+		if attr.Key == "exception.type" {
+			errorType = attr.Value.AsString()
+		}
+	}
+	assert.Contains(t, errorType, "AppError")
+}
+
+func TestVerifyClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotIP string
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotIP = ip
+			return &domain.VerifyResult{
+				IP:      ip,
+				Country: "US",
+				Allowed: true,
+				Reason:  domain.ReasonCountryAllowed,
+			}, nil
+		},
+	}
+
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, clientip.SourceXForwardedFor)
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.POST("/verify/self", VerifyClientIP(resolver, mockService, &MockPolicyService{}))
+
+	body := []byte(`{"allowed_countries":["US"]}`)
+
+	req, _ := http.NewRequest("POST", "/verify/self", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "1.1.1.1, 8.8.8.8")
+	req.RemoteAddr = "10.0.0.2:5000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "8.8.8.8", gotIP)
+	assert.JSONEq(t, `{"ip":"8.8.8.8","country":"US","allowed":true,"reason":"country_allowed","client_ip_source":"x-forwarded-for"}`, w.Body.String())
+}
+
+func TestVerifyClientIP_InvalidForwardingHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, clientip.SourceXForwardedFor)
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.POST("/verify/self", VerifyClientIP(resolver, &MockIPVerifierService{}, &MockPolicyService{}))
+
+	body := []byte(`{"allowed_countries":["US"]}`)
+
+	req, _ := http.NewRequest("POST", "/verify/self", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "garbage")
+	req.RemoteAddr = "10.0.0.2:5000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid X-Forwarded-For entry")
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LookupResponse struct {
+	IP                 string               `json:"ip"`
+	Country            string               `json:"country,omitempty"`
+	Continent          string               `json:"continent,omitempty"`
+	RegisteredCountry  string               `json:"registered_country,omitempty"`
+	RepresentedCountry string               `json:"represented_country,omitempty"`
+	Subdivisions       []string             `json:"subdivisions,omitempty"`
+	City               string               `json:"city,omitempty"`
+	PostalCode         string               `json:"postal_code,omitempty"`
+	Coordinates        *CoordinatesResponse `json:"coordinates,omitempty"`
+	ASN                uint                 `json:"asn,omitempty"`
+	ASOrg              string               `json:"as_org,omitempty"`
+	IsAnonymous        bool                 `json:"is_anonymous,omitempty"`
+	IsVPN              bool                 `json:"is_vpn,omitempty"`
+	IsTor              bool                 `json:"is_tor,omitempty"`
+	IsHosting          bool                 `json:"is_hosting,omitempty"`
+	IsPublicProxy      bool                 `json:"is_public_proxy,omitempty"`
+	RangeType          string               `json:"range_type,omitempty"`
+	DatabaseBuild      string               `json:"database_build,omitempty"`
+}
+
+// LookupIP creates a handler that returns the data known about the IP in
+// the path without applying a policy
+func LookupIP(ipService domain.IPVerifierService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := ipService.LookupIP(c.Request.Context(), c.Param("ip"))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp := LookupResponse{
+			IP:        result.IP,
+			RangeType: string(result.RangeType),
+		}
+		if l := result.Location; l != nil {
+			resp.Country = l.Country
+			resp.Continent = l.Continent
+			resp.RegisteredCountry = l.RegisteredCountry
+			resp.RepresentedCountry = l.RepresentedCountry
+			resp.Subdivisions = l.Subdivisions
+			resp.City = l.City
+			resp.PostalCode = l.PostalCode
+			resp.Coordinates = toCoordinatesResponse(l.Coordinates)
+			resp.DatabaseBuild = formatBuild(l.DatabaseBuild)
+		}
+		if result.ASN != nil {
+			resp.ASN = result.ASN.Number
+			resp.ASOrg = result.ASN.Organization
+		}
+		if a := result.Anonymity; a != nil {
+			resp.IsAnonymous = a.IsAnonymous
+			resp.IsVPN = a.IsVPN
+			resp.IsTor = a.IsTor
+			resp.IsHosting = a.IsHosting
+			resp.IsPublicProxy = a.IsPublicProxy
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockIPVerifierService{
+		LookupIPFunc: func(ctx context.Context, ip string) (*domain.LookupResult, error) {
+			switch ip {
+			case "5.5.5.5":
+				return &domain.LookupResult{
+					IP: ip,
+					Location: &domain.Location{
+						Country:       "NL",
+						Continent:     "EU",
+						DatabaseBuild: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC),
+					},
+					ASN:       &domain.ASN{Number: 64500, Organization: "HOSTING"},
+					Anonymity: &domain.Anonymity{IsAnonymous: true, IsVPN: true},
+				}, nil
+			case "2001:db8::1":
+				return &domain.LookupResult{IP: ip, RangeType: domain.RangeDocumentation}, nil
+			default:
+				return nil, apperrors.NewValidationError("Invalid IP address", nil)
+			}
+		},
+	}
+
+	router := gin.Default()
+	router.GET("/lookup/:ip", LookupIP(mockService))
+
+	tests := []struct {
+		name           string
+		ip             string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "public IP",
+			ip:             "5.5.5.5",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ip":"5.5.5.5","country":"NL","continent":"EU","asn":64500,"as_org":"HOSTING","is_anonymous":true,"is_vpn":true,"database_build":"2026-10-06T00:00:00Z"}`,
+		},
+		{
+			name:           "special range",
+			ip:             "2001:db8::1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ip":"2001:db8::1","range_type":"documentation"}`,
+		},
+		{
+			name:           "invalid IP",
+			ip:             "not-an-ip",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid IP address"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/lookup/"+tt.ip, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyRequest struct {
+	ID               string                 `json:"id"`
+	Description      string                 `json:"description"`
+	AllowedCountries []string               `json:"allowed_countries"`
+	DeniedCountries  []string               `json:"denied_countries"`
+	AllowedASNs      []uint                 `json:"allowed_asns"`
+	DeniedASNs       []uint                 `json:"denied_asns"`
+	AllowedCIDRs     []string               `json:"allowed_cidrs"`
+	DeniedCIDRs      []string               `json:"denied_cidrs"`
+	DeniedAnonymity  []domain.AnonymityType `json:"denied_anonymity"`
+	UnknownAction    string                 `json:"unknown_action"`
+	AllowInternal    bool                   `json:"allow_internal"`
+	MatchMode        string                 `json:"match_mode"`
+}
+
+type PolicyResponse struct {
+	ID               string                 `json:"id"`
+	Description      string                 `json:"description,omitempty"`
+	AllowedCountries []string               `json:"allowed_countries,omitempty"`
+	DeniedCountries  []string               `json:"denied_countries,omitempty"`
+	AllowedASNs      []uint                 `json:"allowed_asns,omitempty"`
+	DeniedASNs       []uint                 `json:"denied_asns,omitempty"`
+	AllowedCIDRs     []string               `json:"allowed_cidrs,omitempty"`
+	DeniedCIDRs      []string               `json:"denied_cidrs,omitempty"`
+	DeniedAnonymity  []domain.AnonymityType `json:"denied_anonymity,omitempty"`
+	UnknownAction    string                 `json:"unknown_action,omitempty"`
+	AllowInternal    bool                   `json:"allow_internal"`
+	MatchMode        string                 `json:"match_mode,omitempty"`
+}
+
+type PolicyListResponse struct {
+	Policies []PolicyResponse `json:"policies"`
+}
+
+// ListPolicies creates a handler that returns all named policies
+func ListPolicies(policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policies, err := policyService.ListPolicies(c.Request.Context())
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp := PolicyListResponse{
+			Policies: make([]PolicyResponse, len(policies)),
+		}
+		for i, policy := range policies {
+			resp.Policies[i] = toPolicyResponse(policy)
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetPolicy creates a handler that returns a single named policy
+func GetPolicy(policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, err := policyService.GetPolicy(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, toPolicyResponse(*policy))
+	}
+}
+
+// CreatePolicy creates a handler that stores a new named policy
+func CreatePolicy(policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var policyReq PolicyRequest
+
+		if err := c.ShouldBindJSON(&policyReq); err != nil {
+			writeError(c, apperrors.NewValidationError(err.Error(), err))
+			return
+		}
+
+		policy, err := policyService.CreatePolicy(c.Request.Context(), fromPolicyRequest(policyReq))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, toPolicyResponse(*policy))
+	}
+}
+
+// UpdatePolicy creates a handler that replaces an existing named policy
+func UpdatePolicy(policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var policyReq PolicyRequest
+
+		if err := c.ShouldBindJSON(&policyReq); err != nil {
+			writeError(c, apperrors.NewValidationError(err.Error(), err))
+			return
+		}
+
+		id := c.Param("id")
+		if policyReq.ID != "" && policyReq.ID != id {
+			writeError(c, apperrors.NewValidationError("policy id in body does not match URL", nil))
+			return
+		}
+		policyReq.ID = id
+
+		policy, err := policyService.UpdatePolicy(c.Request.Context(), fromPolicyRequest(policyReq))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, toPolicyResponse(*policy))
+	}
+}
+
+// DeletePolicy creates a handler that removes a named policy
+func DeletePolicy(policyService domain.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := policyService.DeletePolicy(c.Request.Context(), c.Param("id")); err != nil {
+			writeError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// resolvePolicy returns the named policy referenced by policyID, or an inline
+// policy built from the request's rules. The two are mutually exclusive.
+// An unknown_action sent with the request overrides the named policy's.
+func resolvePolicy(ctx context.Context, policyService domain.PolicyService, policyID string, inline domain.Policy) (domain.Policy, error) {
+	if policyID != "" {
+		if inline.HasRules() || inline.AllowInternal || inline.MatchMode != "" {
+			return domain.Policy{}, apperrors.NewValidationError(
+				"policy_id cannot be combined with inline policy rules", nil)
+		}
+		policy, err := policyService.GetPolicy(ctx, policyID)
+		if err != nil {
+			return domain.Policy{}, err
+		}
+		if inline.UnknownAction != "" {
+			policy.UnknownAction = inline.UnknownAction
+		}
+		return *policy, nil
+	}
+
+	if !inline.HasRules() {
+		return domain.Policy{}, apperrors.NewValidationError(
+			"policy_id or one of allowed_countries, denied_countries, allowed_asns, denied_asns, allowed_cidrs, denied_cidrs or denied_anonymity must be provided", nil)
+	}
+	return inline, nil
+}
+
+// writeError renders an error as the standard JSON error body, which carries
+// the request ID so a reported error can be found in the logs. Server errors
+// are logged with their cause, which the body does not reveal.
+func writeError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	status := apperrors.GetHTTPStatus(err)
+	message := apperrors.GetMessage(err)
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "Request failed", "error", err, "status", status)
+	}
+
+	body := gin.H{"error": message}
+	if id := requestid.FromContext(ctx); id != "" {
+		body["request_id"] = id
+	}
+	c.JSON(status, body)
+}
+
+func fromPolicyRequest(req PolicyRequest) domain.Policy {
+	return domain.Policy{
+		ID:               req.ID,
+		Description:      req.Description,
+		AllowedCountries: req.AllowedCountries,
+		DeniedCountries:  req.DeniedCountries,
+		AllowedASNs:      req.AllowedASNs,
+		DeniedASNs:       req.DeniedASNs,
+		AllowedCIDRs:     req.AllowedCIDRs,
+		DeniedCIDRs:      req.DeniedCIDRs,
+		DeniedAnonymity:  req.DeniedAnonymity,
+		UnknownAction:    domain.UnknownAction(req.UnknownAction),
+		AllowInternal:    req.AllowInternal,
+		MatchMode:        domain.MatchMode(req.MatchMode),
+	}
+}
+
+func toPolicyResponse(policy domain.Policy) PolicyResponse {
+	return PolicyResponse{
+		ID:               policy.ID,
+		Description:      policy.Description,
+		AllowedCountries: policy.AllowedCountries,
+		DeniedCountries:  policy.DeniedCountries,
+		AllowedASNs:      policy.AllowedASNs,
+		DeniedASNs:       policy.DeniedASNs,
+		AllowedCIDRs:     policy.AllowedCIDRs,
+		DeniedCIDRs:      policy.DeniedCIDRs,
+		DeniedAnonymity:  policy.DeniedAnonymity,
+		UnknownAction:    string(policy.UnknownAction),
+		AllowInternal:    policy.AllowInternal,
+		MatchMode:        string(policy.MatchMode),
+	}
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockPolicyService is a mock implementation of domain.PolicyService
+type MockPolicyService struct {
+	ListPoliciesFunc func(ctx context.Context) ([]domain.Policy, error)
+	GetPolicyFunc    func(ctx context.Context, id string) (*domain.Policy, error)
+	CreatePolicyFunc func(ctx context.Context, policy domain.Policy) (*domain.Policy, error)
+	UpdatePolicyFunc func(ctx context.Context, policy domain.Policy) (*domain.Policy, error)
+	DeletePolicyFunc func(ctx context.Context, id string) error
+}
+
+func (m *MockPolicyService) ListPolicies(ctx context.Context) ([]domain.Policy, error) {
+	if m.ListPoliciesFunc != nil {
+		return m.ListPoliciesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockPolicyService) GetPolicy(ctx context.Context, id string) (*domain.Policy, error) {
+	if m.GetPolicyFunc != nil {
+		return m.GetPolicyFunc(ctx, id)
+	}
+	return nil, apperrors.NewNotFoundError("Policy not found", nil)
+}
+
+func (m *MockPolicyService) CreatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	if m.CreatePolicyFunc != nil {
+		return m.CreatePolicyFunc(ctx, policy)
+	}
+	return &policy, nil
+}
+
+func (m *MockPolicyService) UpdatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	if m.UpdatePolicyFunc != nil {
+		return m.UpdatePolicyFunc(ctx, policy)
+	}
+	return &policy, nil
+}
+
+func (m *MockPolicyService) DeletePolicy(ctx context.Context, id string) error {
+	if m.DeletePolicyFunc != nil {
+		return m.DeletePolicyFunc(ctx, id)
+	}
+	return nil
+}
+
+func TestVerifyIP_WithPolicyID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotPolicy domain.Policy
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotPolicy = policy
+			return &domain.VerifyResult{IP: ip, Country: "US", Allowed: true}, nil
+		},
+	}
+	mockPolicies := &MockPolicyService{
+		GetPolicyFunc: func(ctx context.Context, id string) (*domain.Policy, error) {
+			return &domain.Policy{ID: id, AllowedCountries: []string{"US", "CA"}}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(mockService, mockPolicies))
+
+	body := []byte(`{"ip":"8.8.8.8","policy_id":"north-america"}`)
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "north-america", gotPolicy.ID)
+	assert.Equal(t, []string{"US", "CA"}, gotPolicy.AllowedCountries)
+}
+
+func TestVerifyIP_UnknownActionOverridesPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotPolicy domain.Policy
+	mockService := &MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			gotPolicy = policy
+			return &domain.VerifyResult{IP: ip, Allowed: true, Reason: domain.ReasonUnknownLocation}, nil
+		},
+	}
+	mockPolicies := &MockPolicyService{
+		GetPolicyFunc: func(ctx context.Context, id string) (*domain.Policy, error) {
+			return &domain.Policy{ID: id, AllowedCountries: []string{"US"}, UnknownAction: domain.UnknownActionDeny}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(mockService, mockPolicies))
+
+	body := []byte(`{"ip":"10.0.0.5","policy_id":"us-only","unknown_action":"allow"}`)
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.UnknownActionAllow, gotPolicy.UnknownAction)
+	assert.JSONEq(t, `{"ip":"10.0.0.5","allowed":true,"reason":"unknown_location"}`, w.Body.String())
+}
+
+func TestVerifyIP_UnknownPolicyID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(&MockIPVerifierService{}, &MockPolicyService{}))
+
+	body := []byte(`{"ip":"8.8.8.8","policy_id":"missing"}`)
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVerifyIP_PolicyIDWithInlineLists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/verify", VerifyIP(&MockIPVerifierService{}, &MockPolicyService{}))
+
+	body := []byte(`{"ip":"8.8.8.8","policy_id":"north-america","allowed_countries":["US"]}`)
+	req, _ := http.NewRequest("POST", "/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "policy_id cannot be combined")
+}
+
+func TestListPolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockPolicies := &MockPolicyService{
+		ListPoliciesFunc: func(ctx context.Context) ([]domain.Policy, error) {
+			return []domain.Policy{
+				{ID: "eu-only", AllowedCountries: []string{"DE", "FR"}},
+				{ID: "sanctions", Description: "Block sanctioned countries", DeniedCountries: []string{"KP"}},
+			}, nil
+		},
+	}
+
+	router := gin.Default()
+	router.GET("/policies", ListPolicies(mockPolicies))
+
+	req, _ := http.NewRequest("GET", "/policies", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"policies":[
+		{"id":"eu-only","allowed_countries":["DE","FR"],"allow_internal":false},
+		{"id":"sanctions","description":"Block sanctioned countries","denied_countries":["KP"],"allow_internal":false}
+	]}`, w.Body.String())
+}
+
+func TestCreatePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/policies", CreatePolicy(&MockPolicyService{}))
+
+	body, _ := json.Marshal(PolicyRequest{ID: "sanctions", DeniedCountries: []string{"KP", "IR"}})
+	req, _ := http.NewRequest("POST", "/policies", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp PolicyResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "sanctions", resp.ID)
+	assert.Equal(t, []string{"KP", "IR"}, resp.DeniedCountries)
+}
+
+func TestCreatePolicy_Conflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockPolicies := &MockPolicyService{
+		CreatePolicyFunc: func(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+			return nil, apperrors.NewConflictError("Policy \"sanctions\" already exists", nil)
+		},
+	}
+
+	router := gin.Default()
+	router.POST("/policies", CreatePolicy(mockPolicies))
+
+	body, _ := json.Marshal(PolicyRequest{ID: "sanctions", DeniedCountries: []string{"KP"}})
+	req, _ := http.NewRequest("POST", "/policies", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestUpdatePolicy_IDMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.PUT("/policies/:id", UpdatePolicy(&MockPolicyService{}))
+
+	body, _ := json.Marshal(PolicyRequest{ID: "other", DeniedCountries: []string{"KP"}})
+	req, _ := http.NewRequest("PUT", "/policies/sanctions", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeletePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var deleted string
+	mockPolicies := &MockPolicyService{
+		DeletePolicyFunc: func(ctx context.Context, id string) error {
+			deleted = id
+			return nil
+		},
+	}
+
+	router := gin.Default()
+	router.DELETE("/policies/:id", DeletePolicy(mockPolicies))
+
+	req, _ := http.NewRequest("DELETE", "/policies/sanctions", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "sanctions", deleted)
+}
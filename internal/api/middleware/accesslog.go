@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// verificationKey is the gin context key of the verification decision
+// reported to the access log
+const verificationKey = "ip-verifier.verification"
+
+// IPAnonymiser rewrites a client IP before it is written to the access log
+type IPAnonymiser func(netip.Addr) string
+
+// TruncateIP keeps the network part of an address: the /24 of IPv4 and the
+// /48 of IPv6 addresses (e.g., 203.0.113.7 is logged as 203.0.113.0)
+func TruncateIP(addr netip.Addr) string {
+	bits := 48
+	if addr.Is4() || addr.Is4In6() {
+		addr = addr.Unmap()
+		bits = 24
+	}
+	return netip.PrefixFrom(addr, bits).Masked().Addr().String()
+}
+
+// HashIP returns an anonymiser that logs a keyed hash of the address, so
+// requests from the same client can be correlated without the address
+// being recoverable by anyone who does not hold the key
+func HashIP(key []byte) IPAnonymiser {
+	return func(addr netip.Addr) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(addr.Unmap().AsSlice())
+		return hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+}
+
+type accessLog struct {
+	level      slog.Level
+	sampleRate float64
+	anonymise  IPAnonymiser
+}
+
+// AccessLogOption configures AccessLog
+type AccessLogOption func(*accessLog)
+
+// WithAccessLogLevel sets the level of entries for requests that did not
+// fail with a server error, which are always logged at error level
+func WithAccessLogLevel(level slog.Level) AccessLogOption {
+	return func(l *accessLog) {
+		l.level = level
+	}
+}
+
+// WithSampleRate logs only the given fraction (0 to 1) of requests that did
+// not fail with a server error. Server errors are always logged.
+func WithSampleRate(rate float64) AccessLogOption {
+	return func(l *accessLog) {
+		l.sampleRate = rate
+	}
+}
+
+// WithIPAnonymiser rewrites client IPs before they are logged. A nil
+// anonymiser logs them as they are.
+func WithIPAnonymiser(anonymise IPAnonymiser) AccessLogOption {
+	return func(l *accessLog) {
+		if anonymise != nil {
+			l.anonymise = anonymise
+		}
+	}
+}
+
+// AccessLog writes one entry per request to logger with its method, route,
+// status, latency, client IP and, for verifications, the decision. The
+// client IP is resolved like the handlers do, so forwarding headers are
+// honoured only from trusted proxies. Entries are logged with the request
+// context, so a logger wrapping requestid.NewLogHandler adds the request ID.
+func AccessLog(logger *slog.Logger, resolver *clientip.Resolver, opts ...AccessLogOption) gin.HandlerFunc {
+	l := &accessLog{
+		level:      slog.LevelInfo,
+		sampleRate: 1,
+		anonymise:  netip.Addr.String,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		status := c.Writer.Status()
+		level := l.level
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if l.sampleRate < 1 && rand.Float64() >= l.sampleRate {
+			return
+		}
+
+		ctx := c.Request.Context()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+
+		attrs := make([]slog.Attr, 0, 10)
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+		}
+		attrs = append(attrs, slog.String("method", c.Request.Method))
+		// The route pattern rather than the path keeps looked up IPs out of
+		// the log; the path is only logged when no route matched
+		if route := c.FullPath(); route != "" {
+			attrs = append(attrs, slog.String("route", route))
+		} else {
+			attrs = append(attrs, slog.String("path", c.Request.URL.Path))
+		}
+		attrs = append(attrs,
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+		)
+		if ip, _, err := resolver.Resolve(c.Request); err == nil {
+			attrs = append(attrs, slog.String("client_ip", l.anonymise(ip)))
+		}
+		if value, ok := c.Get(verificationKey); ok {
+			result := value.(*domain.VerifyResult)
+			attrs = append(attrs, slog.Group("verification",
+				slog.Bool("allowed", result.Allowed),
+				slog.String("reason", string(result.Reason)),
+				slog.String("country", result.Country),
+			))
+		}
+
+		logger.LogAttrs(ctx, level, "HTTP request", attrs...)
+	}
+}
+
+// SetVerification reports the decision of a single verification made while
+// handling the request to the access log
+func SetVerification(c *gin.Context, result *domain.VerifyResult) {
+	c.Set(verificationKey, result)
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAccessLogRouter returns a router logging to the returned buffer, with a
+// verification route, a failing route and a lookup route
+func newAccessLogRouter(t *testing.T, opts ...AccessLogOption) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := slog.New(requestid.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, clientip.SourceXForwardedFor)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logger, resolver, opts...))
+	router.POST("/api/v1/ip-verifier", func(c *gin.Context) {
+		SetVerification(c, &domain.VerifyResult{Country: "DE", Allowed: false, Reason: domain.ReasonCountryDenied})
+		c.Status(http.StatusOK)
+	})
+	router.GET("/api/v1/ip-verifier/lookup/:ip", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	return router, &buf
+}
+
+// serve sends a request from remoteAddr and returns the decoded log entries
+func serve(router *gin.Engine, buf *bytes.Buffer, method, path, remoteAddr string, header http.Header) []map[string]any {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	buf.Reset()
+	return entries
+}
+
+func TestAccessLog(t *testing.T) {
+	router, buf := newAccessLogRouter(t)
+
+	entries := serve(router, buf, "POST", "/api/v1/ip-verifier", "10.0.0.1:4000", http.Header{
+		"X-Forwarded-For": {"203.0.113.7"},
+		"X-Request-Id":    {"req-123"},
+	})
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "HTTP request", entry["msg"])
+	assert.Equal(t, "req-123", entry["request_id"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/api/v1/ip-verifier", entry["route"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Contains(t, entry, "latency_ms")
+	assert.Equal(t, "203.0.113.7", entry["client_ip"], "forwarded client IP of a trusted proxy")
+	assert.Equal(t, map[string]any{"allowed": false, "reason": "country_denied", "country": "DE"}, entry["verification"])
+}
+
+func TestAccessLog_RouteInsteadOfPath(t *testing.T) {
+	router, buf := newAccessLogRouter(t)
+
+	entries := serve(router, buf, "GET", "/api/v1/ip-verifier/lookup/198.51.100.1", "192.0.2.1:4000", nil)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/api/v1/ip-verifier/lookup/:ip", entries[0]["route"])
+	assert.NotContains(t, entries[0], "path")
+	assert.NotContains(t, entries[0], "verification")
+
+	entries = serve(router, buf, "GET", "/nope", "192.0.2.1:4000", nil)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/nope", entries[0]["path"])
+	assert.NotContains(t, entries[0], "route")
+}
+
+func TestAccessLog_Levels(t *testing.T) {
+	router, buf := newAccessLogRouter(t, WithAccessLogLevel(slog.LevelDebug))
+
+	// Debug entries are below the level of the logger
+	assert.Empty(t, serve(router, buf, "GET", "/api/v1/ip-verifier/lookup/8.8.8.8", "192.0.2.1:4000", nil))
+
+	entries := serve(router, buf, "GET", "/fail", "192.0.2.1:4000", nil)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ERROR", entries[0]["level"])
+}
+
+func TestAccessLog_Sampling(t *testing.T) {
+	router, buf := newAccessLogRouter(t, WithSampleRate(0))
+
+	assert.Empty(t, serve(router, buf, "GET", "/api/v1/ip-verifier/lookup/8.8.8.8", "192.0.2.1:4000", nil))
+	assert.Len(t, serve(router, buf, "GET", "/fail", "192.0.2.1:4000", nil), 1, "server errors are always logged")
+
+	router, buf = newAccessLogRouter(t, WithSampleRate(0.5))
+	logged := 0
+	for range 1000 {
+		logged += len(serve(router, buf, "GET", "/api/v1/ip-verifier/lookup/8.8.8.8", "192.0.2.1:4000", nil))
+	}
+	assert.InDelta(t, 500, logged, 100)
+}
+
+func TestAccessLog_IPAnonymisation(t *testing.T) {
+	tests := []struct {
+		name       string
+		anonymiser IPAnonymiser
+		remoteAddr string
+		expected   string
+	}{
+		{"none", nil, "203.0.113.7:4000", "203.0.113.7"},
+		{"truncate IPv4", TruncateIP, "203.0.113.7:4000", "203.0.113.0"},
+		{"truncate IPv6", TruncateIP, "[2001:db8:1234:5678::1]:4000", "2001:db8:1234::"},
+		{"hash", HashIP([]byte("secret")), "203.0.113.7:4000", HashIP([]byte("secret"))(netip.MustParseAddr("203.0.113.7"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, buf := newAccessLogRouter(t, WithIPAnonymiser(tt.anonymiser))
+
+			entries := serve(router, buf, "GET", "/api/v1/ip-verifier/lookup/8.8.8.8", tt.remoteAddr, nil)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.expected, entries[0]["client_ip"])
+		})
+	}
+}
+
+func TestHashIP(t *testing.T) {
+	hash := HashIP([]byte("secret"))
+	addr := netip.MustParseAddr("203.0.113.7")
+
+	assert.Len(t, hash(addr), 32)
+	assert.NotContains(t, hash(addr), "203.0.113")
+	assert.Equal(t, hash(addr), hash(netip.MustParseAddr("::ffff:203.0.113.7")), "mapped addresses hash like IPv4")
+	assert.NotEqual(t, hash(addr), hash(netip.MustParseAddr("203.0.113.8")))
+	assert.NotEqual(t, hash(addr), HashIP([]byte("other"))(addr), "the hash depends on the key")
+}
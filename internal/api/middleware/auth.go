@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireBearerToken rejects requests whose Authorization header does not
+// carry the given bearer token. An empty token rejects every request.
+func RequireBearerToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			body := gin.H{"error": "Unauthorized"}
+			if id := requestid.FromContext(c.Request.Context()); id != "" {
+				body["request_id"] = id
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, body)
+			return
+		}
+		c.Next()
+	}
+}
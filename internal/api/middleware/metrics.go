@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordMetrics records the count and latency of every request by its route
+// pattern, method and status code. Register it before gin.Recovery, so
+// requests whose handler panicked are counted with their 500.
+func RecordMetrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.ObserveRequest(c.FullPath(), c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
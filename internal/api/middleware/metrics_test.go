@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/metrics"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reg := prometheus.NewRegistry()
+	router := gin.New()
+	router.Use(RecordMetrics(metrics.New(reg)))
+	router.GET("/api/v1/ip-verifier/lookup/:ip", func(c *gin.Context) {
+		if c.Param("ip") == "bad" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid IP address"})
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/ip-verifier/lookup/8.8.8.8", "/api/v1/ip-verifier/lookup/1.1.1.1", "/api/v1/ip-verifier/lookup/bad", "/nope"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+# HELP ip_verifier_http_requests_total HTTP requests by route, method and status code.
+# TYPE ip_verifier_http_requests_total counter
+ip_verifier_http_requests_total{method="GET",route="/api/v1/ip-verifier/lookup/:ip",status="200"} 2
+ip_verifier_http_requests_total{method="GET",route="/api/v1/ip-verifier/lookup/:ip",status="400"} 1
+ip_verifier_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "ip_verifier_http_requests_total"))
+	count, err := testutil.GatherAndCount(reg, "ip_verifier_http_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestRecordMetrics_Panic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reg := prometheus.NewRegistry()
+	router := gin.New()
+	router.Use(RecordMetrics(metrics.New(reg)), gin.RecoveryWithWriter(io.Discard))
+	router.GET("/api/v1/ip-verifier/lookup/:ip", func(c *gin.Context) {
+		panic("lookup failed")
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v1/ip-verifier/lookup/8.8.8.8", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	expected := `
+# HELP ip_verifier_http_requests_total HTTP requests by route, method and status code.
+# TYPE ip_verifier_http_requests_total counter
+ip_verifier_http_requests_total{method="GET",route="/api/v1/ip-verifier/lookup/:ip",status="500"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "ip_verifier_http_requests_total"))
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID assigns every request an ID: the caller's X-Request-ID when it
+// is valid, otherwise a generated one. The ID is stored in the request
+// context, for logs and error bodies, and echoed in the X-Request-ID
+// response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+		c.Next()
+	}
+}
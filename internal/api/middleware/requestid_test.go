@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/requestid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var contextID string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		contextID = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		accepted bool
+	}{
+		{"accepted from caller", "3f2b8c1e-9d4a-4e6b-8f0a-2c5d7e9b1a3f", true},
+		{"generated when missing", "", false},
+		{"generated when invalid", "bad id", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Request-ID", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get("X-Request-ID")
+			assert.True(t, requestid.Valid(id))
+			assert.Equal(t, id, contextID)
+			if tt.accepted {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.NotEqual(t, tt.header, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing/tracingtest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracingtest.Install()
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/api/v1/ip-verifier/lookup/:ip", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		if c.Param("ip") == "fail" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		spanName string
+		route    string
+		status   int
+		code     codes.Code
+	}{
+		{"matched route", "/api/v1/ip-verifier/lookup/8.8.8.8", "GET /api/v1/ip-verifier/lookup/:ip", "/api/v1/ip-verifier/lookup/:ip", http.StatusOK, codes.Unset},
+		{"server error", "/api/v1/ip-verifier/lookup/fail", "GET /api/v1/ip-verifier/lookup/:ip", "/api/v1/ip-verifier/lookup/:ip", http.StatusInternalServerError, codes.Error},
+		{"unmatched route", "/nope", "GET", "", http.StatusNotFound, codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, parent := tracingtest.NewParent(t.Context())
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			req.Header.Set("traceparent", "00-"+parent.TraceID().String()+"-"+parent.SpanID().String()+"-01")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+
+			spans := tracingtest.Spans(parent.TraceID())
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, tt.spanName, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, parent.SpanID(), span.Parent().SpanID())
+			assert.True(t, span.Parent().IsRemote())
+			assert.Equal(t, tt.code, span.Status().Code)
+			assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", tt.status))
+			if tt.route != "" {
+				assert.Contains(t, span.Attributes(), attribute.String("http.route", tt.route))
+				assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+			}
+		})
+	}
+}
+
+func TestTracing_NewTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracingtest.Install()
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/api/v1/health", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/api/v1/health", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, handlerSpan.IsValid())
+	spans := tracingtest.Spans(handlerSpan.TraceID())
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent().IsValid())
+}
@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +15,10 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Batch    BatchConfig
+	Policy   PolicyConfig
+	Tracing  TracingConfig
+	Log      LogConfig
 }
 
 // ServerConfig holds HTTP server configuration
@@ -20,11 +28,54 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
 	Environment     string
+	TrustedProxies  []string // CIDRs of reverse proxies whose forwarding headers are honoured
+	ClientIPHeader  string   // The forwarding header the trusted proxies set (forwarded, x-forwarded-for or x-real-ip)
+	ExtAuthzPort    string   // Port of the Envoy ext_authz gRPC server (empty disables it)
+	GRPCPort        string   // Port of the native gRPC API (empty disables it)
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	GeoIPPath string
+	GeoIPPath      string
+	CityPath       string        // Optional GeoLite2-City database for subdivision, city and coordinate data
+	ASNPath        string        // Optional GeoLite2-ASN database for ASN data and rules
+	AnonymousPath  string        // Optional GeoIP2-Anonymous-IP database for VPN, hosting and proxy detection
+	TorExitPath    string        // Optional file of Tor exit node addresses
+	ReloadInterval time.Duration // How often to poll the database files for changes (0 disables polling)
+}
+
+// BatchConfig holds batch verification configuration
+type BatchConfig struct {
+	MaxSize     int // Maximum number of IPs in one batch request
+	Concurrency int // Number of lookups run in parallel per batch
+}
+
+// PolicyConfig holds named policy configuration
+type PolicyConfig struct {
+	File       string // Optional JSON file with named policies loaded at startup
+	AdminToken string // Bearer token required by the policy admin endpoints (empty disables auth)
+}
+
+// TracingConfig holds OpenTelemetry trace export configuration
+type TracingConfig struct {
+	Endpoint    string // OTLP/gRPC collector URL (empty disables export)
+	ServiceName string // Service name reported on exported spans
+}
+
+// IP anonymisation modes of the access log
+const (
+	IPAnonymisationNone     = "none"     // Log client IPs as they are
+	IPAnonymisationTruncate = "truncate" // Log the /24 (IPv4) or /48 (IPv6) network only
+	IPAnonymisationHash     = "hash"     // Log a keyed hash of the IP
+)
+
+// LogConfig holds logging configuration
+type LogConfig struct {
+	Level           slog.Level // Minimum level of all log entries
+	AccessLevel     slog.Level // Level of access log entries for requests without a server error
+	SampleRate      float64    // Fraction of requests without a server error written to the access log
+	IPAnonymisation string     // How client IPs are written to the access log (none, truncate or hash)
+	IPHashKey       string     // HMAC key of the hash anonymisation mode
 }
 
 // Load reads configuration from environment variables with sensible defaults
@@ -36,9 +87,37 @@ func Load() (*Config, error) {
 			WriteTimeout:    getDurationEnv("WRITE_TIMEOUT", 10*time.Second),
 			ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
 			Environment:     getEnv("ENVIRONMENT", "development"),
+			TrustedProxies:  getListEnv("TRUSTED_PROXIES"),
+			ClientIPHeader:  strings.ToLower(getEnv("CLIENT_IP_HEADER", "x-forwarded-for")),
+			ExtAuthzPort:    getEnv("EXT_AUTHZ_PORT", ""),
+			GRPCPort:        getEnv("GRPC_PORT", ""),
 		},
 		Database: DatabaseConfig{
-			GeoIPPath: getEnv("GEOIP_DB_PATH", "data/GeoLite2-Country.mmdb"),
+			GeoIPPath:      getEnv("GEOIP_DB_PATH", "data/GeoLite2-Country.mmdb"),
+			CityPath:       getEnv("GEOIP_CITY_DB_PATH", ""),
+			ASNPath:        getEnv("GEOIP_ASN_DB_PATH", ""),
+			AnonymousPath:  getEnv("GEOIP_ANONYMOUS_IP_DB_PATH", ""),
+			TorExitPath:    getEnv("TOR_EXIT_LIST_PATH", ""),
+			ReloadInterval: getDurationEnv("GEOIP_RELOAD_INTERVAL", time.Minute),
+		},
+		Batch: BatchConfig{
+			MaxSize:     getIntEnv("BATCH_MAX_SIZE", 1000),
+			Concurrency: getIntEnv("BATCH_CONCURRENCY", 16),
+		},
+		Policy: PolicyConfig{
+			File:       getEnv("POLICY_FILE", ""),
+			AdminToken: getEnv("ADMIN_TOKEN", ""),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "ip-verifier"),
+		},
+		Log: LogConfig{
+			Level:           getLevelEnv("LOG_LEVEL", slog.LevelInfo),
+			AccessLevel:     getLevelEnv("ACCESS_LOG_LEVEL", slog.LevelInfo),
+			SampleRate:      getFloatEnv("ACCESS_LOG_SAMPLE_RATE", 1),
+			IPAnonymisation: getEnv("LOG_IP_ANONYMISATION", IPAnonymisationNone),
+			IPHashKey:       getEnv("LOG_IP_HASH_KEY", ""),
 		},
 	}
 
@@ -60,11 +139,78 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("GeoIP database path cannot be empty")
 	}
 
+	if c.Database.ReloadInterval < 0 {
+		return fmt.Errorf("GeoIP reload interval cannot be negative")
+	}
+
 	// Validate port is a valid number
 	if _, err := strconv.Atoi(c.Server.Port); err != nil {
 		return fmt.Errorf("invalid port number: %s", c.Server.Port)
 	}
 
+	if c.Server.ExtAuthzPort != "" {
+		if _, err := strconv.Atoi(c.Server.ExtAuthzPort); err != nil {
+			return fmt.Errorf("invalid ext_authz port number: %s", c.Server.ExtAuthzPort)
+		}
+		if c.Server.ExtAuthzPort == c.Server.Port {
+			return fmt.Errorf("ext_authz port must differ from the server port")
+		}
+	}
+
+	if c.Server.GRPCPort != "" {
+		if _, err := strconv.Atoi(c.Server.GRPCPort); err != nil {
+			return fmt.Errorf("invalid gRPC port number: %s", c.Server.GRPCPort)
+		}
+		if c.Server.GRPCPort == c.Server.Port || c.Server.GRPCPort == c.Server.ExtAuthzPort {
+			return fmt.Errorf("gRPC port must differ from the server and ext_authz ports")
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				return fmt.Errorf("invalid trusted proxy: %s", proxy)
+			}
+		}
+	}
+
+	if len(c.Server.TrustedProxies) > 0 {
+		switch c.Server.ClientIPHeader {
+		case "forwarded", "x-forwarded-for", "x-real-ip":
+		default:
+			return fmt.Errorf("invalid client IP header, expected forwarded, x-forwarded-for or x-real-ip: %s", c.Server.ClientIPHeader)
+		}
+	}
+
+	if c.Batch.MaxSize <= 0 {
+		return fmt.Errorf("batch max size must be positive")
+	}
+
+	if c.Batch.Concurrency <= 0 {
+		return fmt.Errorf("batch concurrency must be positive")
+	}
+
+	if c.Log.SampleRate < 0 || c.Log.SampleRate > 1 {
+		return fmt.Errorf("access log sample rate must be between 0 and 1")
+	}
+
+	switch c.Log.IPAnonymisation {
+	case "", IPAnonymisationNone, IPAnonymisationTruncate:
+	case IPAnonymisationHash:
+		if c.Log.IPHashKey == "" {
+			return fmt.Errorf("LOG_IP_HASH_KEY is required by the hash IP anonymisation mode")
+		}
+	default:
+		return fmt.Errorf("invalid IP anonymisation mode: %s", c.Log.IPAnonymisation)
+	}
+
+	if c.Tracing.Endpoint != "" {
+		endpoint, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("invalid OTLP endpoint, expected http(s)://host:port: %s", c.Tracing.Endpoint)
+		}
+	}
+
 	return nil
 }
 
@@ -73,6 +219,16 @@ func (c *Config) GetAddress() string {
 	return ":" + c.Server.Port
 }
 
+// GetExtAuthzAddress returns the ext_authz gRPC server address (e.g., ":9001")
+func (c *Config) GetExtAuthzAddress() string {
+	return ":" + c.Server.ExtAuthzPort
+}
+
+// GetGRPCAddress returns the gRPC API server address (e.g., ":9000")
+func (c *Config) GetGRPCAddress() string {
+	return ":" + c.Server.GRPCPort
+}
+
 // IsProduction returns true if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
@@ -86,6 +242,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getListEnv retrieves a comma-separated list from environment, skipping empty items
+func getListEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getDurationEnv retrieves a duration from environment or returns default
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -95,3 +262,35 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getFloatEnv retrieves a float from environment or returns default
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// getLevelEnv retrieves a log level (debug, info, warn or error) from
+// environment or returns default
+func getLevelEnv(key string, defaultValue slog.Level) slog.Level {
+	if value := os.Getenv(key); value != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err == nil {
+			return level
+		}
+	}
+	return defaultValue
+}
+
+// getIntEnv retrieves an integer from environment or returns default
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
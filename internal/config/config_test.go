@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 	"testing"
 	"time"
@@ -21,7 +22,27 @@ func TestLoad_DefaultValues(t *testing.T) {
 	assert.Equal(t, 10*time.Second, config.Server.WriteTimeout)
 	assert.Equal(t, 30*time.Second, config.Server.ShutdownTimeout)
 	assert.Equal(t, "development", config.Server.Environment)
+	assert.Empty(t, config.Server.TrustedProxies)
+	assert.Equal(t, "x-forwarded-for", config.Server.ClientIPHeader)
+	assert.Empty(t, config.Server.ExtAuthzPort)
+	assert.Empty(t, config.Server.GRPCPort)
 	assert.Equal(t, "data/GeoLite2-Country.mmdb", config.Database.GeoIPPath)
+	assert.Empty(t, config.Database.CityPath)
+	assert.Empty(t, config.Database.ASNPath)
+	assert.Empty(t, config.Database.AnonymousPath)
+	assert.Empty(t, config.Database.TorExitPath)
+	assert.Equal(t, time.Minute, config.Database.ReloadInterval)
+	assert.Equal(t, 1000, config.Batch.MaxSize)
+	assert.Equal(t, 16, config.Batch.Concurrency)
+	assert.Empty(t, config.Policy.File)
+	assert.Empty(t, config.Policy.AdminToken)
+	assert.Empty(t, config.Tracing.Endpoint)
+	assert.Equal(t, "ip-verifier", config.Tracing.ServiceName)
+	assert.Equal(t, slog.LevelInfo, config.Log.Level)
+	assert.Equal(t, slog.LevelInfo, config.Log.AccessLevel)
+	assert.Equal(t, 1.0, config.Log.SampleRate)
+	assert.Equal(t, IPAnonymisationNone, config.Log.IPAnonymisation)
+	assert.Empty(t, config.Log.IPHashKey)
 }
 
 func TestLoad_CustomValues(t *testing.T) {
@@ -30,7 +51,27 @@ func TestLoad_CustomValues(t *testing.T) {
 	os.Setenv("WRITE_TIMEOUT", "5s")
 	os.Setenv("SHUTDOWN_TIMEOUT", "15s")
 	os.Setenv("ENVIRONMENT", "production")
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1,")
+	os.Setenv("CLIENT_IP_HEADER", "X-Real-IP")
+	os.Setenv("EXT_AUTHZ_PORT", "9001")
+	os.Setenv("GRPC_PORT", "9002")
 	os.Setenv("GEOIP_DB_PATH", "/custom/path/GeoLite2.mmdb")
+	os.Setenv("GEOIP_CITY_DB_PATH", "/custom/path/GeoLite2-City.mmdb")
+	os.Setenv("GEOIP_ASN_DB_PATH", "/custom/path/GeoLite2-ASN.mmdb")
+	os.Setenv("GEOIP_ANONYMOUS_IP_DB_PATH", "/custom/path/GeoIP2-Anonymous-IP.mmdb")
+	os.Setenv("TOR_EXIT_LIST_PATH", "/custom/path/tor-exits.txt")
+	os.Setenv("GEOIP_RELOAD_INTERVAL", "5m")
+	os.Setenv("BATCH_MAX_SIZE", "50")
+	os.Setenv("BATCH_CONCURRENCY", "4")
+	os.Setenv("POLICY_FILE", "/etc/ip-verifier/policies.json")
+	os.Setenv("ADMIN_TOKEN", "secret")
+	os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel-collector:4317")
+	os.Setenv("OTEL_SERVICE_NAME", "ip-verifier-eu")
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("ACCESS_LOG_LEVEL", "warn")
+	os.Setenv("ACCESS_LOG_SAMPLE_RATE", "0.25")
+	os.Setenv("LOG_IP_ANONYMISATION", "hash")
+	os.Setenv("LOG_IP_HASH_KEY", "hash-secret")
 	defer os.Clearenv()
 
 	config, err := Load()
@@ -41,7 +82,27 @@ func TestLoad_CustomValues(t *testing.T) {
 	assert.Equal(t, 5*time.Second, config.Server.WriteTimeout)
 	assert.Equal(t, 15*time.Second, config.Server.ShutdownTimeout)
 	assert.Equal(t, "production", config.Server.Environment)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1"}, config.Server.TrustedProxies)
+	assert.Equal(t, "x-real-ip", config.Server.ClientIPHeader)
+	assert.Equal(t, "9001", config.Server.ExtAuthzPort)
+	assert.Equal(t, "9002", config.Server.GRPCPort)
 	assert.Equal(t, "/custom/path/GeoLite2.mmdb", config.Database.GeoIPPath)
+	assert.Equal(t, "/custom/path/GeoLite2-City.mmdb", config.Database.CityPath)
+	assert.Equal(t, "/custom/path/GeoLite2-ASN.mmdb", config.Database.ASNPath)
+	assert.Equal(t, "/custom/path/GeoIP2-Anonymous-IP.mmdb", config.Database.AnonymousPath)
+	assert.Equal(t, "/custom/path/tor-exits.txt", config.Database.TorExitPath)
+	assert.Equal(t, 5*time.Minute, config.Database.ReloadInterval)
+	assert.Equal(t, 50, config.Batch.MaxSize)
+	assert.Equal(t, 4, config.Batch.Concurrency)
+	assert.Equal(t, "/etc/ip-verifier/policies.json", config.Policy.File)
+	assert.Equal(t, "secret", config.Policy.AdminToken)
+	assert.Equal(t, "http://otel-collector:4317", config.Tracing.Endpoint)
+	assert.Equal(t, "ip-verifier-eu", config.Tracing.ServiceName)
+	assert.Equal(t, slog.LevelDebug, config.Log.Level)
+	assert.Equal(t, slog.LevelWarn, config.Log.AccessLevel)
+	assert.Equal(t, 0.25, config.Log.SampleRate)
+	assert.Equal(t, IPAnonymisationHash, config.Log.IPAnonymisation)
+	assert.Equal(t, "hash-secret", config.Log.IPHashKey)
 }
 
 func TestValidate_InvalidPort(t *testing.T) {
@@ -89,6 +150,211 @@ func TestValidate_EmptyGeoIPPath(t *testing.T) {
 	assert.Contains(t, err.Error(), "GeoIP database path cannot be empty")
 }
 
+func TestValidate_NegativeReloadInterval(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			Port: "8080",
+		},
+		Database: DatabaseConfig{
+			GeoIPPath:      "data/GeoLite2-Country.mmdb",
+			ReloadInterval: -time.Second,
+		},
+	}
+
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "reload interval cannot be negative")
+}
+
+func TestValidate_InvalidBatchSize(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			Port: "8080",
+		},
+		Database: DatabaseConfig{
+			GeoIPPath: "data/GeoLite2-Country.mmdb",
+		},
+		Batch: BatchConfig{
+			MaxSize:     0,
+			Concurrency: 4,
+		},
+	}
+
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "batch max size must be positive")
+}
+
+func TestValidate_InvalidTrustedProxy(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			Port:           "8080",
+			TrustedProxies: []string{"10.0.0.0/8", "10.0.0.0/33"},
+		},
+		Database: DatabaseConfig{
+			GeoIPPath: "data/GeoLite2-Country.mmdb",
+		},
+		Batch: BatchConfig{
+			MaxSize:     10,
+			Concurrency: 4,
+		},
+	}
+
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid trusted proxy: 10.0.0.0/33")
+}
+
+func TestValidate_InvalidClientIPHeader(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			Port:           "8080",
+			TrustedProxies: []string{"10.0.0.0/8"},
+			ClientIPHeader: "true-client-ip",
+		},
+		Database: DatabaseConfig{
+			GeoIPPath: "data/GeoLite2-Country.mmdb",
+		},
+		Batch: BatchConfig{
+			MaxSize:     10,
+			Concurrency: 4,
+		},
+	}
+
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid client IP header")
+}
+
+func TestValidate_InvalidExtAuthzPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		expected string
+	}{
+		{"not a number", "grpc", "invalid ext_authz port number: grpc"},
+		{"same as server port", "8080", "ext_authz port must differ from the server port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Server: ServerConfig{
+					Port:         "8080",
+					ExtAuthzPort: tt.port,
+				},
+				Database: DatabaseConfig{
+					GeoIPPath: "data/GeoLite2-Country.mmdb",
+				},
+				Batch: BatchConfig{
+					MaxSize:     10,
+					Concurrency: 4,
+				},
+			}
+
+			err := config.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestValidate_InvalidGRPCPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		expected string
+	}{
+		{"not a number", "grpc", "invalid gRPC port number: grpc"},
+		{"same as server port", "8080", "gRPC port must differ from the server and ext_authz ports"},
+		{"same as ext_authz port", "9001", "gRPC port must differ from the server and ext_authz ports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Server: ServerConfig{
+					Port:         "8080",
+					ExtAuthzPort: "9001",
+					GRPCPort:     tt.port,
+				},
+				Database: DatabaseConfig{
+					GeoIPPath: "data/GeoLite2-Country.mmdb",
+				},
+				Batch: BatchConfig{
+					MaxSize:     10,
+					Concurrency: 4,
+				},
+			}
+
+			err := config.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestValidate_InvalidOTLPEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"otel-collector:4317", "grpc://otel-collector:4317", "http://", "://otel-collector"} {
+		t.Run(endpoint, func(t *testing.T) {
+			config := &Config{
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Database: DatabaseConfig{
+					GeoIPPath: "data/GeoLite2-Country.mmdb",
+				},
+				Batch: BatchConfig{
+					MaxSize:     10,
+					Concurrency: 4,
+				},
+				Tracing: TracingConfig{
+					Endpoint: endpoint,
+				},
+			}
+
+			err := config.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid OTLP endpoint")
+		})
+	}
+}
+
+func TestValidate_InvalidLogConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      LogConfig
+		expected string
+	}{
+		{"negative sample rate", LogConfig{SampleRate: -0.1}, "sample rate"},
+		{"sample rate above 1", LogConfig{SampleRate: 1.5}, "sample rate"},
+		{"unknown anonymisation", LogConfig{SampleRate: 1, IPAnonymisation: "mask"}, "invalid IP anonymisation mode"},
+		{"hash without key", LogConfig{SampleRate: 1, IPAnonymisation: IPAnonymisationHash}, "LOG_IP_HASH_KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Database: DatabaseConfig{
+					GeoIPPath: "data/GeoLite2-Country.mmdb",
+				},
+				Batch: BatchConfig{
+					MaxSize:     10,
+					Concurrency: 4,
+				},
+				Log: tt.log,
+			}
+
+			err := config.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
 func TestGetAddress(t *testing.T) {
 	config := &Config{
 		Server: ServerConfig{
@@ -1,22 +1,138 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // IPVerifierRepo defines the interface for IP geolocation data access
 type IPVerifierRepo interface {
-	GetCountryByIP(ctx context.Context, ipAddress string) (string, error)
+	GetCountryByIP(ctx context.Context, ipAddress string) (*Location, error)
+	GetASNByIP(ctx context.Context, ipAddress string) (*ASN, error)
+	GetAnonymityByIP(ctx context.Context, ipAddress string) (*Anonymity, error)
 	HealthCheck(ctx context.Context) error
 }
 
 // IPVerifierService defines the interface for IP verification business logic
 type IPVerifierService interface {
-	VerifyIP(ctx context.Context, ip string, allowedCountries []string) (*VerifyResult, error)
+	VerifyIP(ctx context.Context, ip string, policy Policy) (*VerifyResult, error)
+	VerifyIPs(ctx context.Context, ips []string, policy Policy) ([]BatchResult, error)
+	LookupIP(ctx context.Context, ip string) (*LookupResult, error)
 	HealthCheck(ctx context.Context) error
 }
 
+// Location represents the geolocation data found for an IP address
+type Location struct {
+	Country            string // Physical country, ISO 3166-1 alpha-2 code
+	Continent          string // Two-letter continent code of the physical country (e.g., "EU")
+	RegisteredCountry  string // Country the IP block is registered to (e.g., by the ISP)
+	RepresentedCountry string // Country represented by users of the IP (e.g., military bases)
+
+	// Populated only when a City database is configured
+	Subdivisions []string     // ISO 3166-2 subdivision codes without the country prefix, largest first (e.g., "CA")
+	City         string       // English city name
+	PostalCode   string       // Postal or ZIP code
+	Coordinates  *Coordinates // Approximate location of the IP
+
+	DatabaseBuild time.Time // Build time of the database the location was read from
+}
+
+// ASN identifies the autonomous system that announces an IP address
+type ASN struct {
+	Number       uint   // Autonomous system number (e.g., 15169)
+	Organization string // Organization registered for the AS (e.g., "GOOGLE")
+}
+
+// Anonymity flags the anonymising services an IP address is known to belong to
+type Anonymity struct {
+	IsAnonymous   bool // Any kind of anonymising service
+	IsVPN         bool // Anonymous VPN provider
+	IsTor         bool // Tor exit node
+	IsHosting     bool // Hosting or VPN provider network
+	IsPublicProxy bool // Open public proxy
+}
+
+// Coordinates is the approximate position of an IP address
+type Coordinates struct {
+	Latitude       float64
+	Longitude      float64
+	AccuracyRadius uint16 // Radius in kilometers around the coordinates
+}
+
 // VerifyResult represents the result of an IP verification
 type VerifyResult struct {
-	IP      string
-	Country string
-	Allowed bool
+	IP                 string
+	Country            string
+	Continent          string
+	RegisteredCountry  string
+	RepresentedCountry string
+	Subdivisions       []string
+	City               string
+	PostalCode         string
+	Coordinates        *Coordinates
+	ASN                uint // Zero when no ASN data is available
+	ASOrg              string
+	Anonymity          Anonymity // All false when no anonymity data is available
+	Allowed            bool
+	Reason             Reason
+	RangeType          RangeType    // Set when the IP is in a special-purpose range
+	MatchedGroup       string       // Continent or group entry that decided the result (e.g., "group:EU")
+	MatchedRule        *MatchedRule // Policy entry that decided the result; nil when none did
+	DatabaseBuild      time.Time    // Build time of the GeoIP database used; zero when no lookup was made
+}
+
+// LookupResult represents the data known about an IP, without a policy decision
+type LookupResult struct {
+	IP        string
+	Location  *Location  // nil when the database has no country for the IP
+	ASN       *ASN       // nil when no ASN data is available
+	Anonymity *Anonymity // nil when no anonymity data is available
+	RangeType RangeType  // Set when the IP is in a special-purpose range; no lookups are made then
+}
+
+// MatchedRule identifies the policy entry that decided a verification
+type MatchedRule struct {
+	List  string // Policy field holding the entry (e.g., "denied_countries", "allow_internal")
+	Entry string // The entry in canonical form (e.g., "group:EU", "15169", "10.0.0.0/8", "vpn")
+}
+
+// Reason explains why a verification was allowed or denied
+type Reason string
+
+const (
+	ReasonCountryAllowed  Reason = "country_allowed"
+	ReasonCountryDenied   Reason = "country_denied"
+	ReasonNotInAllowlist  Reason = "not_in_allowlist"
+	ReasonUnknownLocation Reason = "unknown_location"
+	ReasonPrivateRange    Reason = "private_range"
+	ReasonASNAllowed      Reason = "asn_allowed"
+	ReasonASNDenied       Reason = "asn_denied"
+	ReasonCIDRAllowed     Reason = "cidr_allowed"
+	ReasonCIDRDenied      Reason = "cidr_denied"
+	ReasonAnonymousDenied Reason = "anonymous_denied"
+)
+
+// RangeType identifies an IANA special-purpose address range
+type RangeType string
+
+const (
+	RangePrivate       RangeType = "private"
+	RangeCGNAT         RangeType = "cgnat"
+	RangeLoopback      RangeType = "loopback"
+	RangeLinkLocal     RangeType = "link_local"
+	RangeUniqueLocal   RangeType = "unique_local"
+	RangeDocumentation RangeType = "documentation"
+	RangeBenchmarking  RangeType = "benchmarking"
+	RangeMulticast     RangeType = "multicast"
+	RangeBroadcast     RangeType = "broadcast"
+	RangeThisNetwork   RangeType = "this_network"
+	RangeUnspecified   RangeType = "unspecified"
+	RangeReserved      RangeType = "reserved"
+)
+
+// BatchResult represents the outcome of verifying one IP in a batch.
+// Exactly one of Result and Err is set.
+type BatchResult struct {
+	Result *VerifyResult
+	Err    error
 }
@@ -0,0 +1,109 @@
+package domain
+
+import "context"
+
+// Policy holds the country rules an IP is verified against.
+// A country in DeniedCountries is always rejected. When AllowedCountries is
+// non-empty, only countries in it are accepted; otherwise every country that
+// is not denied is accepted.
+//
+// Besides ISO country codes, list entries may reference a continent
+// ("continent:EU") or a built-in country group ("group:EU", "group:EEA",
+// "group:SCHENGEN").
+//
+// AllowedASNs and DeniedASNs match the autonomous system announcing the IP
+// regardless of geography. A denied ASN is always rejected and an allowed ASN
+// is accepted before any country rule is checked. When only AllowedASNs is
+// set, IPs from other networks are rejected.
+//
+// DeniedAnonymity rejects VPN, Tor, hosting or public proxy traffic
+// regardless of country. Only an allowed ASN or CIDR admits such an IP.
+//
+// AllowedCIDRs and DeniedCIDRs override every other rule for the IP ranges
+// they list and are checked before any database lookup. The most specific
+// matching prefix wins; for identical prefixes the deny entry wins. Like
+// AllowedASNs, AllowedCIDRs without AllowedCountries rejects every other IP.
+//
+// UnknownAction decides what happens to IPs the database has no country for.
+// AllowInternal admits private, CGNAT, loopback, link-local and unique local
+// addresses regardless of the country rules.
+// MatchMode selects which of the location's countries the rules apply to.
+//
+// Named policies are stored server-side and have an ID; inline policies sent
+// with a verify request leave ID and Description empty.
+type Policy struct {
+	ID               string
+	Description      string
+	AllowedCountries []string
+	DeniedCountries  []string
+	AllowedASNs      []uint
+	DeniedASNs       []uint
+	AllowedCIDRs     []string
+	DeniedCIDRs      []string
+	DeniedAnonymity  []AnonymityType
+	UnknownAction    UnknownAction
+	AllowInternal    bool
+	MatchMode        MatchMode
+}
+
+// HasRules reports whether the policy has at least one allow or deny list
+func (p Policy) HasRules() bool {
+	return len(p.AllowedCountries) > 0 || len(p.DeniedCountries) > 0 ||
+		len(p.AllowedASNs) > 0 || len(p.DeniedASNs) > 0 ||
+		len(p.AllowedCIDRs) > 0 || len(p.DeniedCIDRs) > 0 ||
+		len(p.DeniedAnonymity) > 0
+}
+
+// AnonymityType is a category of anonymised traffic a policy can deny
+type AnonymityType string
+
+const (
+	AnonymityVPN         AnonymityType = "vpn"
+	AnonymityTor         AnonymityType = "tor"
+	AnonymityHosting     AnonymityType = "hosting"
+	AnonymityPublicProxy AnonymityType = "public_proxy"
+)
+
+// MatchMode selects which countries of a location are checked against a policy
+type MatchMode string
+
+const (
+	// MatchPhysical checks the physical country (the default)
+	MatchPhysical MatchMode = "physical"
+	// MatchRegistered checks the country the IP block is registered to
+	MatchRegistered MatchMode = "registered"
+	// MatchAny allows the IP if any known country passes the rules
+	MatchAny MatchMode = "any"
+	// MatchAll allows the IP only if every known country passes the rules
+	MatchAll MatchMode = "all"
+)
+
+// UnknownAction is the outcome for an IP without a known country
+type UnknownAction string
+
+const (
+	// UnknownActionDeny rejects the IP (the default)
+	UnknownActionDeny UnknownAction = "deny"
+	// UnknownActionAllow accepts the IP
+	UnknownActionAllow UnknownAction = "allow"
+	// UnknownActionError fails the verification with a not found error
+	UnknownActionError UnknownAction = "error"
+)
+
+// PolicyRepo defines the interface for policy storage
+type PolicyRepo interface {
+	List(ctx context.Context) ([]Policy, error)
+	Get(ctx context.Context, id string) (*Policy, error)
+	Create(ctx context.Context, policy Policy) error
+	Update(ctx context.Context, policy Policy) error
+	Delete(ctx context.Context, id string) error
+}
+
+// PolicyService defines the interface for policy management business logic
+type PolicyService interface {
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	GetPolicy(ctx context.Context, id string) (*Policy, error)
+	CreatePolicy(ctx context.Context, policy Policy) (*Policy, error)
+	UpdatePolicy(ctx context.Context, policy Policy) (*Policy, error)
+	DeletePolicy(ctx context.Context, id string) error
+}
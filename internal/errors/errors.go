@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"google.golang.org/grpc/codes"
 )
 
 // AppError represents a custom application error with HTTP status code
@@ -42,6 +44,15 @@ func NewNotFoundError(message string, err error) *AppError {
 	}
 }
 
+// NewConflictError creates a conflict error (409 Conflict)
+func NewConflictError(message string, err error) *AppError {
+	return &AppError{
+		Code:        http.StatusConflict,
+		Message:     message,
+		InternalErr: err,
+	}
+}
+
 // NewInternalError creates an internal server error (500 Internal Server Error)
 func NewInternalError(message string, err error) *AppError {
 	return &AppError{
@@ -60,6 +71,20 @@ func GetHTTPStatus(err error) int {
 	return http.StatusInternalServerError
 }
 
+// GetGRPCCode maps the error's HTTP status code to a gRPC status code
+func GetGRPCCode(err error) codes.Code {
+	switch GetHTTPStatus(err) {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	default:
+		return codes.Internal
+	}
+}
+
 // GetMessage extracts user-facing message from error
 func GetMessage(err error) string {
 	var appErr *AppError
@@ -87,6 +112,15 @@ func IsNotFoundError(err error) bool {
 	return false
 }
 
+// IsConflictError checks if error is a conflict error
+func IsConflictError(err error) bool {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code == http.StatusConflict
+	}
+	return false
+}
+
 // IsInternalError checks if error is an internal error
 func IsInternalError(err error) bool {
 	var appErr *AppError
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 )
 
 func TestAppError_Error(t *testing.T) {
@@ -71,6 +72,15 @@ func TestNewNotFoundError(t *testing.T) {
 	assert.Equal(t, internalErr, err.InternalErr)
 }
 
+func TestNewConflictError(t *testing.T) {
+	internalErr := fmt.Errorf("duplicate key")
+	err := NewConflictError("Resource already exists", internalErr)
+
+	assert.Equal(t, http.StatusConflict, err.Code)
+	assert.Equal(t, "Resource already exists", err.Message)
+	assert.Equal(t, internalErr, err.InternalErr)
+}
+
 func TestNewInternalError(t *testing.T) {
 	internalErr := fmt.Errorf("database connection failed")
 	err := NewInternalError("Internal server error", internalErr)
@@ -96,6 +106,11 @@ func TestGetHTTPStatus(t *testing.T) {
 			err:            NewNotFoundError("Not found", nil),
 			expectedStatus: http.StatusNotFound,
 		},
+		{
+			name:           "conflict error",
+			err:            NewConflictError("Conflict", nil),
+			expectedStatus: http.StatusConflict,
+		},
 		{
 			name:           "internal error",
 			err:            NewInternalError("Server error", nil),
@@ -116,6 +131,26 @@ func TestGetHTTPStatus(t *testing.T) {
 	}
 }
 
+func TestGetGRPCCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+	}{
+		{"validation error", NewValidationError("Invalid input", nil), codes.InvalidArgument},
+		{"not found error", NewNotFoundError("Not found", nil), codes.NotFound},
+		{"conflict error", NewConflictError("Conflict", nil), codes.AlreadyExists},
+		{"internal error", NewInternalError("Server error", nil), codes.Internal},
+		{"generic error", fmt.Errorf("generic error"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedCode, GetGRPCCode(tt.err))
+		})
+	}
+}
+
 func TestGetMessage(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -156,6 +191,13 @@ func TestIsNotFoundError(t *testing.T) {
 	assert.False(t, IsNotFoundError(errors.New("generic")))
 }
 
+func TestIsConflictError(t *testing.T) {
+	assert.True(t, IsConflictError(NewConflictError("test", nil)))
+	assert.False(t, IsConflictError(NewValidationError("test", nil)))
+	assert.False(t, IsConflictError(NewNotFoundError("test", nil)))
+	assert.False(t, IsConflictError(errors.New("generic")))
+}
+
 func TestIsInternalError(t *testing.T) {
 	assert.True(t, IsInternalError(NewInternalError("test", nil)))
 	assert.False(t, IsInternalError(NewValidationError("test", nil)))
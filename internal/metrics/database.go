@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Database is a GeoIP database whose build time is exported
+type Database interface {
+	Path() string
+	DatabaseType() string
+	BuildEpoch() time.Time
+}
+
+var (
+	databaseBuildDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "database", "build_timestamp_seconds"),
+		"Build time of the loaded GeoIP database as a Unix timestamp.",
+		[]string{"database", "path"}, nil,
+	)
+	databaseAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "database", "age_seconds"),
+		"Seconds since the loaded GeoIP database was built.",
+		[]string{"database", "path"}, nil,
+	)
+)
+
+// databaseCollector reads the build time at scrape time, so reloaded
+// databases are reported without re-registering
+type databaseCollector struct {
+	databases []Database
+	now       func() time.Time
+}
+
+// NewDatabaseCollector exports the build time and age of the databases
+func NewDatabaseCollector(databases ...Database) prometheus.Collector {
+	return &databaseCollector{databases: databases, now: time.Now}
+}
+
+func (c *databaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- databaseBuildDesc
+	ch <- databaseAgeDesc
+}
+
+func (c *databaseCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, db := range c.databases {
+		build := db.BuildEpoch()
+		if build.IsZero() {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(databaseBuildDesc, prometheus.GaugeValue,
+			float64(build.Unix()), db.DatabaseType(), db.Path())
+		ch <- prometheus.MustNewConstMetric(databaseAgeDesc, prometheus.GaugeValue,
+			c.now().Sub(build).Seconds(), db.DatabaseType(), db.Path())
+	}
+}
@@ -0,0 +1,92 @@
+// Package metrics defines the Prometheus metrics exported on /metrics.
+// Collectors are registered with a registry passed in by the caller, so the
+// process and tests each use their own.
+package metrics
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "ip_verifier"
+
+// Label values for verification outcomes, IPs without a country and
+// requests without a route
+const (
+	outcomeAllowed = "allowed"
+	outcomeDenied  = "denied"
+
+	unknownCountry = "unknown"
+	unmatchedRoute = "unmatched"
+)
+
+// Metrics records HTTP traffic, verification decisions and lookup errors
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	verifications   *prometheus.CounterVec
+	lookupErrors    *prometheus.CounterVec
+}
+
+// New creates the metrics and registers them with reg
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status code.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"route", "method", "status"}),
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "verifications_total",
+			Help:      "IP verifications by country and outcome (allowed or denied).",
+		}, []string{"country", "outcome"}),
+		lookupErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lookup_errors_total",
+			Help:      "Failed verifications and lookups by error status code.",
+		}, []string{"code"}),
+	}
+	reg.MustRegister(m.requests, m.requestDuration, m.verifications, m.lookupErrors)
+	return m
+}
+
+// ObserveRequest records a finished HTTP request. route is the matched route
+// pattern (e.g., "/api/v1/ip-verifier/lookup/:ip"), or "" when none matched.
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	labels := prometheus.Labels{"route": route, "method": method, "status": strconv.Itoa(status)}
+	m.requests.With(labels).Inc()
+	m.requestDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveVerification records the decision of a verification
+func (m *Metrics) ObserveVerification(result *domain.VerifyResult) {
+	country := result.Country
+	if country == "" {
+		country = unknownCountry
+	}
+	outcome := outcomeDenied
+	if result.Allowed {
+		outcome = outcomeAllowed
+	}
+	m.verifications.WithLabelValues(country, outcome).Inc()
+}
+
+// ObserveError records a failed verification or lookup by the HTTP status
+// of its AppError
+func (m *Metrics) ObserveError(err error) {
+	m.lookupErrors.WithLabelValues(strconv.Itoa(apperrors.GetHTTPStatus(err))).Inc()
+}
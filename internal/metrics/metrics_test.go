@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIPVerifierService is a mock implementation of domain.IPVerifierService
+type MockIPVerifierService struct {
+	VerifyIPFunc    func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error)
+	VerifyIPsFunc   func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error)
+	LookupIPFunc    func(ctx context.Context, ip string) (*domain.LookupResult, error)
+	HealthCheckFunc func(ctx context.Context) error
+}
+
+func (m *MockIPVerifierService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+	return m.VerifyIPFunc(ctx, ip, policy)
+}
+
+func (m *MockIPVerifierService) VerifyIPs(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+	return m.VerifyIPsFunc(ctx, ips, policy)
+}
+
+func (m *MockIPVerifierService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	return m.LookupIPFunc(ctx, ip)
+}
+
+func (m *MockIPVerifierService) HealthCheck(ctx context.Context) error {
+	return m.HealthCheckFunc(ctx)
+}
+
+func TestObserveRequest(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	m.ObserveRequest("/api/v1/ip-verifier", "POST", 200, 2*time.Millisecond)
+	m.ObserveRequest("/api/v1/ip-verifier", "POST", 200, 3*time.Millisecond)
+	m.ObserveRequest("", "GET", 404, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("/api/v1/ip-verifier", "POST", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "GET", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.requestDuration))
+}
+
+func TestInstrumentService(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	svc := InstrumentService(&MockIPVerifierService{
+		VerifyIPFunc: func(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+			switch ip {
+			case "8.8.8.8":
+				return &domain.VerifyResult{IP: ip, Country: "US", Allowed: true}, nil
+			case "10.0.0.1":
+				return &domain.VerifyResult{IP: ip, RangeType: domain.RangePrivate}, nil
+			}
+			return nil, apperrors.NewValidationError("Invalid IP address", nil)
+		},
+		VerifyIPsFunc: func(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+			if len(ips) > 2 {
+				return nil, apperrors.NewValidationError("batch cannot contain more than 2 IPs", nil)
+			}
+			return []domain.BatchResult{
+				{Result: &domain.VerifyResult{IP: ips[0], Country: "RU"}},
+				{Err: apperrors.NewInternalError("Failed to lookup IP address", errors.New("boom"))},
+			}, nil
+		},
+		LookupIPFunc: func(ctx context.Context, ip string) (*domain.LookupResult, error) {
+			return nil, apperrors.NewInternalError("Failed to lookup IP address", errors.New("boom"))
+		},
+	}, m)
+
+	ctx := context.Background()
+	_, _ = svc.VerifyIP(ctx, "8.8.8.8", domain.Policy{})
+	_, _ = svc.VerifyIP(ctx, "10.0.0.1", domain.Policy{})
+	_, err := svc.VerifyIP(ctx, "bad", domain.Policy{})
+	assert.True(t, apperrors.IsValidationError(err), "errors are passed through")
+
+	results, err := svc.VerifyIPs(ctx, []string{"77.88.8.8", "8.8.4.4"}, domain.Policy{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	_, err = svc.VerifyIPs(ctx, []string{"a", "b", "c"}, domain.Policy{})
+	assert.Error(t, err)
+
+	_, err = svc.LookupIP(ctx, "8.8.8.8")
+	assert.Error(t, err)
+
+	expected := `
+# HELP ip_verifier_lookup_errors_total Failed verifications and lookups by error status code.
+# TYPE ip_verifier_lookup_errors_total counter
+ip_verifier_lookup_errors_total{code="400"} 1
+ip_verifier_lookup_errors_total{code="500"} 2
+# HELP ip_verifier_verifications_total IP verifications by country and outcome (allowed or denied).
+# TYPE ip_verifier_verifications_total counter
+ip_verifier_verifications_total{country="RU",outcome="denied"} 1
+ip_verifier_verifications_total{country="US",outcome="allowed"} 1
+ip_verifier_verifications_total{country="unknown",outcome="denied"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"ip_verifier_lookup_errors_total", "ip_verifier_verifications_total"))
+}
+
+type fakeDatabase struct {
+	path         string
+	databaseType string
+	build        time.Time
+}
+
+func (d fakeDatabase) Path() string          { return d.path }
+func (d fakeDatabase) DatabaseType() string  { return d.databaseType }
+func (d fakeDatabase) BuildEpoch() time.Time { return d.build }
+
+func TestDatabaseCollector(t *testing.T) {
+	build := time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)
+	collector := NewDatabaseCollector(
+		fakeDatabase{"data/GeoLite2-Country.mmdb", "GeoLite2-Country", build},
+		fakeDatabase{"data/empty.mmdb", "GeoLite2-ASN", time.Time{}},
+	).(*databaseCollector)
+	collector.now = func() time.Time { return build.Add(36 * time.Hour) }
+
+	expected := `
+# HELP ip_verifier_database_age_seconds Seconds since the loaded GeoIP database was built.
+# TYPE ip_verifier_database_age_seconds gauge
+ip_verifier_database_age_seconds{database="GeoLite2-Country",path="data/GeoLite2-Country.mmdb"} 129600
+# HELP ip_verifier_database_build_timestamp_seconds Build time of the loaded GeoIP database as a Unix timestamp.
+# TYPE ip_verifier_database_build_timestamp_seconds gauge
+ip_verifier_database_build_timestamp_seconds{database="GeoLite2-Country",path="data/GeoLite2-Country.mmdb"} 1.7912448e+09
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+)
+
+type instrumentedService struct {
+	domain.IPVerifierService
+	metrics *Metrics
+}
+
+// InstrumentService wraps an IPVerifierService so that every decision and
+// lookup error is recorded, whichever API (HTTP, gRPC or ext_authz) made the
+// call
+func InstrumentService(next domain.IPVerifierService, m *Metrics) domain.IPVerifierService {
+	return &instrumentedService{IPVerifierService: next, metrics: m}
+}
+
+func (s *instrumentedService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+	result, err := s.IPVerifierService.VerifyIP(ctx, ip, policy)
+	if err != nil {
+		s.metrics.ObserveError(err)
+		return nil, err
+	}
+	s.metrics.ObserveVerification(result)
+	return result, nil
+}
+
+// VerifyIPs records each item of the batch. A batch rejected as a whole
+// (e.g., for its size) is a request error and is not counted.
+func (s *instrumentedService) VerifyIPs(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
+	results, err := s.IPVerifierService.VerifyIPs(ctx, ips, policy)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range results {
+		if item.Err != nil {
+			s.metrics.ObserveError(item.Err)
+			continue
+		}
+		s.metrics.ObserveVerification(item.Result)
+	}
+	return results, nil
+}
+
+func (s *instrumentedService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	result, err := s.IPVerifierService.LookupIP(ctx, ip)
+	if err != nil {
+		s.metrics.ObserveError(err)
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Database is a hot-reloadable handle to a MaxMind database file.
+// Lookups hold a read lock for their duration, so Reload can swap in a new
+// reader and close the old one once in-flight lookups have drained.
+//
+// Readers memory-map the file, so updates must replace it atomically
+// (write to a temp file and rename), as geoipupdate does.
+type Database struct {
+	path string
+
+	// reloadMu serialises reloads triggered by the watcher and by SIGHUP
+	reloadMu sync.Mutex
+
+	mu      sync.RWMutex
+	reader  *geoip2.Reader
+	modTime time.Time
+	size    int64
+}
+
+// OpenDatabase opens the MaxMind database at path
+func OpenDatabase(path string) (*Database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{
+		path:    path,
+		reader:  reader,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}, nil
+}
+
+// Path returns the file the database was opened from
+func (d *Database) Path() string {
+	return d.path
+}
+
+// DatabaseType returns the MaxMind database type (e.g., "GeoLite2-Country")
+func (d *Database) DatabaseType() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.reader.Metadata().DatabaseType
+}
+
+// BuildEpoch returns the build time of the currently loaded database
+func (d *Database) BuildEpoch() time.Time {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return buildEpoch(d.reader)
+}
+
+// view runs fn against the current reader while holding the read lock
+func (d *Database) view(fn func(reader *geoip2.Reader) error) error {
+	if d == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return fn(d.reader)
+}
+
+// Reload opens the file again, validates it and swaps it in for the current
+// reader. On any error the current reader is left in place.
+func (d *Database) Reload() error {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", d.path, err)
+	}
+
+	reader, err := geoip2.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", d.path, err)
+	}
+
+	oldType := d.DatabaseType()
+	if newType := reader.Metadata().DatabaseType; newType != oldType {
+		reader.Close()
+		return fmt.Errorf("database type changed from %q to %q", oldType, newType)
+	}
+
+	// Taking the write lock waits for in-flight lookups on the old reader
+	d.mu.Lock()
+	old := d.reader
+	d.reader = reader
+	d.modTime = info.ModTime()
+	d.size = info.Size()
+	d.mu.Unlock()
+
+	if err := old.Close(); err != nil {
+		slog.Warn("Failed to close previous GeoIP database", "error", err, "path", d.path)
+	}
+
+	slog.Info("GeoIP database reloaded",
+		"path", d.path,
+		"database_type", oldType,
+		"old_build_epoch", buildEpoch(old),
+		"new_build_epoch", buildEpoch(reader),
+	)
+	return nil
+}
+
+// Watch polls the database file every interval and reloads it when its
+// modification time or size changes. It blocks until ctx is cancelled.
+func (d *Database) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !d.changed() {
+				continue
+			}
+			if err := d.Reload(); err != nil {
+				// Keep serving the old data and retry on the next tick;
+				// the updater may still be writing the file.
+				slog.Error("Failed to reload GeoIP database", "error", err, "path", d.path)
+			}
+		}
+	}
+}
+
+// changed reports whether the file on disk differs from the loaded one
+func (d *Database) changed() bool {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return !info.ModTime().Equal(d.modTime) || info.Size() != d.size
+}
+
+// Close releases the current reader
+func (d *Database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reader.Close()
+}
+
+func buildEpoch(reader *geoip2.Reader) time.Time {
+	return time.Unix(int64(reader.Metadata().BuildEpoch), 0).UTC()
+}
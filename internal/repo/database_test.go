@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDatabasePath = "../../data/GeoLite2-Country.mmdb"
+
+// copyTestDatabase copies the GeoLite2 test database into a temp dir so it can be rewritten
+func copyTestDatabase(t *testing.T) string {
+	t.Helper()
+
+	src, err := os.Open(testDatabasePath)
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+	}
+	defer src.Close()
+
+	path := filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb")
+	dst, err := os.Create(path)
+	require.NoError(t, err)
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	require.NoError(t, err)
+	return path
+}
+
+// replaceFile atomically replaces path the way geoipupdate does
+func replaceFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, data, 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestOpenDatabase_MissingFile(t *testing.T) {
+	db, err := OpenDatabase(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+	assert.Nil(t, db)
+}
+
+func TestOpenDatabase_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("not a maxmind database"), 0o644))
+
+	db, err := OpenDatabase(path)
+	assert.Error(t, err)
+	assert.Nil(t, db)
+}
+
+func TestReload_InvalidFileKeepsCurrentReader(t *testing.T) {
+	path := copyTestDatabase(t)
+
+	db, err := OpenDatabase(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	replaceFile(t, path, []byte("truncated"))
+
+	err = db.Reload()
+	assert.Error(t, err)
+
+	repo := NewIPVerifierRepo(db)
+	location, err := repo.GetCountryByIP(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", location.Country)
+}
+
+func TestReload_ConcurrentLookups(t *testing.T) {
+	path := copyTestDatabase(t)
+
+	db, err := OpenDatabase(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIPVerifierRepo(db)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				location, err := repo.GetCountryByIP(ctx, "8.8.8.8")
+				if assert.NoError(t, err) {
+					assert.Equal(t, "US", location.Country)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, db.Reload())
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestWatch_ReloadsChangedFile(t *testing.T) {
+	path := copyTestDatabase(t)
+
+	db, err := OpenDatabase(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go db.Watch(ctx, 10*time.Millisecond)
+
+	// Bump the modification time so the watcher sees a new file
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	assert.Eventually(t, func() bool {
+		return !db.changed()
+	}, time.Second, 10*time.Millisecond)
+}
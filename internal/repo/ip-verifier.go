@@ -2,37 +2,245 @@ package repo
 
 import (
 	"context"
-	"ip-verifier/internal/domain"
-	apperrors "ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing"
 	"net"
+	"net/netip"
+	"time"
 
 	"github.com/oschwald/geoip2-golang"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = otel.Tracer("github.com/KWilliams-dev/ip-verifier/internal/repo")
+
 type IPVerifierRepo struct {
-	db *geoip2.Reader
+	db   *Database
+	city *Database
+	asn  *Database
+
+	anonymous *Database
+	tor       *TorExitList
+}
+
+// Option configures optional databases of an IPVerifierRepo
+type Option func(*IPVerifierRepo)
+
+// WithCityDatabase looks up locations in a GeoLite2-City database, which adds
+// subdivisions, city, postal code and coordinates to the country data.
+// A nil database is ignored.
+func WithCityDatabase(city *Database) Option {
+	return func(r *IPVerifierRepo) {
+		r.city = city
+	}
+}
+
+// WithASNDatabase enables ASN lookups from a GeoLite2-ASN database.
+// A nil database is ignored.
+func WithASNDatabase(asn *Database) Option {
+	return func(r *IPVerifierRepo) {
+		r.asn = asn
+	}
+}
+
+// WithAnonymousIPDatabase enables anonymity lookups from a database in the
+// GeoIP2-Anonymous-IP format. A nil database is ignored.
+func WithAnonymousIPDatabase(anonymous *Database) Option {
+	return func(r *IPVerifierRepo) {
+		r.anonymous = anonymous
+	}
+}
+
+// WithTorExitList flags the addresses of a Tor exit node list as Tor.
+// A nil list is ignored.
+func WithTorExitList(tor *TorExitList) Option {
+	return func(r *IPVerifierRepo) {
+		r.tor = tor
+	}
 }
 
 // NewIPVerifierRepo creates a new IPVerifierRepo that implements domain.IPVerifierRepo
-func NewIPVerifierRepo(db *geoip2.Reader) domain.IPVerifierRepo {
-	return &IPVerifierRepo{
+func NewIPVerifierRepo(db *Database, opts ...Option) domain.IPVerifierRepo {
+	r := &IPVerifierRepo{
 		db: db,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// GetCountryByIP retrieves the physical, registered and represented country
+// and the continent for a given IP address. It returns a not found error when
+// the database has no country at all for the IP, as is the case for private,
+// reserved and unmapped addresses.
+//
+// When a City database is configured it is used instead of the Country
+// database and the location also carries subdivisions, city, postal code and
+// coordinates.
+func (r *IPVerifierRepo) GetCountryByIP(ctx context.Context, ipAddress string) (location *domain.Location, err error) {
+	_, span := tracer.Start(ctx, "IPVerifierRepo.GetCountryByIP")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, apperrors.NewValidationError("Invalid IP address", nil)
+	}
+
+	database := "country"
+	if r.city != nil {
+		database = "city"
+		location, err = r.lookupCity(ip)
+	} else {
+		location, err = r.lookupCountry(ip)
+	}
+	span.SetAttributes(attribute.String("geoip.database", database))
+	if err != nil {
+		return nil, apperrors.NewInternalError("Failed to lookup IP address", err)
+	}
+	span.SetAttributes(attribute.String("geoip.country", location.Country))
+
+	if location.Country == "" && location.RegisteredCountry == "" && location.RepresentedCountry == "" {
+		return nil, apperrors.NewNotFoundError("No country found for IP address", nil)
+	}
+	return location, nil
+}
+
+// lookupCountry reads the country data from the Country database
+func (r *IPVerifierRepo) lookupCountry(ip net.IP) (*domain.Location, error) {
+	var (
+		record *geoip2.Country
+		build  time.Time
+	)
+	err := r.db.view(func(reader *geoip2.Reader) error {
+		var err error
+		record, err = reader.Country(ip)
+		build = buildEpoch(reader)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Location{
+		Country:            record.Country.IsoCode,
+		Continent:          record.Continent.Code,
+		RegisteredCountry:  record.RegisteredCountry.IsoCode,
+		RepresentedCountry: record.RepresentedCountry.IsoCode,
+		DatabaseBuild:      build,
+	}, nil
 }
 
-// GetCountryByIP retrieves the country code for a given IP address
-func (r *IPVerifierRepo) GetCountryByIP(ctx context.Context, ipAddress string) (string, error) {
+// lookupCity reads the country and city data from the City database
+func (r *IPVerifierRepo) lookupCity(ip net.IP) (*domain.Location, error) {
+	var (
+		record *geoip2.City
+		build  time.Time
+	)
+	err := r.city.view(func(reader *geoip2.Reader) error {
+		var err error
+		record, err = reader.City(ip)
+		build = buildEpoch(reader)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	location := &domain.Location{
+		Country:            record.Country.IsoCode,
+		Continent:          record.Continent.Code,
+		RegisteredCountry:  record.RegisteredCountry.IsoCode,
+		RepresentedCountry: record.RepresentedCountry.IsoCode,
+		City:               record.City.Names["en"],
+		PostalCode:         record.Postal.Code,
+		DatabaseBuild:      build,
+	}
+	for _, subdivision := range record.Subdivisions {
+		if subdivision.IsoCode != "" {
+			location.Subdivisions = append(location.Subdivisions, subdivision.IsoCode)
+		}
+	}
+	if record.Location.Latitude != 0 || record.Location.Longitude != 0 {
+		location.Coordinates = &domain.Coordinates{
+			Latitude:       record.Location.Latitude,
+			Longitude:      record.Location.Longitude,
+			AccuracyRadius: record.Location.AccuracyRadius,
+		}
+	}
+	return location, nil
+}
+
+// GetASNByIP retrieves the autonomous system for a given IP address. It
+// returns nil without an error when no ASN database is configured or the
+// database has no entry for the IP.
+func (r *IPVerifierRepo) GetASNByIP(ctx context.Context, ipAddress string) (*domain.ASN, error) {
 	ip := net.ParseIP(ipAddress)
 	if ip == nil {
-		return "", apperrors.NewValidationError("Invalid IP address", nil)
+		return nil, apperrors.NewValidationError("Invalid IP address", nil)
+	}
+	if r.asn == nil {
+		return nil, nil
 	}
 
-	record, err := r.db.Country(ip)
+	var record *geoip2.ASN
+	err := r.asn.view(func(reader *geoip2.Reader) error {
+		var err error
+		record, err = reader.ASN(ip)
+		return err
+	})
 	if err != nil {
-		return "", apperrors.NewInternalError("Failed to lookup IP address", err)
+		return nil, apperrors.NewInternalError("Failed to lookup ASN", err)
+	}
+
+	if record.AutonomousSystemNumber == 0 {
+		return nil, nil
+	}
+	return &domain.ASN{
+		Number:       record.AutonomousSystemNumber,
+		Organization: record.AutonomousSystemOrganization,
+	}, nil
+}
+
+// GetAnonymityByIP reports whether an IP address belongs to a VPN, Tor,
+// hosting or public proxy network, combining the Anonymous IP database and
+// the Tor exit list. It returns nil without an error when neither source is
+// configured.
+func (r *IPVerifierRepo) GetAnonymityByIP(ctx context.Context, ipAddress string) (*domain.Anonymity, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, apperrors.NewValidationError("Invalid IP address", nil)
+	}
+	if r.anonymous == nil && r.tor == nil {
+		return nil, nil
+	}
+
+	anonymity := &domain.Anonymity{}
+	if r.anonymous != nil {
+		var record *geoip2.AnonymousIP
+		err := r.anonymous.view(func(reader *geoip2.Reader) error {
+			var err error
+			record, err = reader.AnonymousIP(ip)
+			return err
+		})
+		if err != nil {
+			return nil, apperrors.NewInternalError("Failed to lookup anonymous IP data", err)
+		}
+
+		anonymity.IsAnonymous = record.IsAnonymous
+		anonymity.IsVPN = record.IsAnonymousVPN
+		anonymity.IsTor = record.IsTorExitNode
+		anonymity.IsHosting = record.IsHostingProvider
+		anonymity.IsPublicProxy = record.IsPublicProxy
 	}
 
-	return record.Country.IsoCode, nil
+	if addr, ok := netip.AddrFromSlice(ip); ok && r.tor.Contains(addr) {
+		anonymity.IsTor = true
+		anonymity.IsAnonymous = true
+	}
+	return anonymity, nil
 }
 
 // HealthCheck verifies the GeoIP database is accessible
@@ -41,9 +249,42 @@ func (r *IPVerifierRepo) HealthCheck(ctx context.Context) error {
 		return apperrors.NewInternalError("GeoIP database not initialized", nil)
 	}
 	// Try a simple lookup to verify DB is working
-	_, err := r.db.Country(net.ParseIP("8.8.8.8"))
+	err := r.db.view(func(reader *geoip2.Reader) error {
+		_, err := reader.Country(net.ParseIP("8.8.8.8"))
+		return err
+	})
 	if err != nil {
 		return apperrors.NewInternalError("GeoIP database health check failed", err)
 	}
+
+	if r.city != nil {
+		err := r.city.view(func(reader *geoip2.Reader) error {
+			_, err := reader.City(net.ParseIP("8.8.8.8"))
+			return err
+		})
+		if err != nil {
+			return apperrors.NewInternalError("GeoIP City database health check failed", err)
+		}
+	}
+
+	if r.asn != nil {
+		err := r.asn.view(func(reader *geoip2.Reader) error {
+			_, err := reader.ASN(net.ParseIP("8.8.8.8"))
+			return err
+		})
+		if err != nil {
+			return apperrors.NewInternalError("GeoIP ASN database health check failed", err)
+		}
+	}
+
+	if r.anonymous != nil {
+		err := r.anonymous.view(func(reader *geoip2.Reader) error {
+			_, err := reader.AnonymousIP(net.ParseIP("8.8.8.8"))
+			return err
+		})
+		if err != nil {
+			return apperrors.NewInternalError("GeoIP Anonymous IP database health check failed", err)
+		}
+	}
 	return nil
 }
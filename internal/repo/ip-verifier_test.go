@@ -2,10 +2,16 @@ package repo
 
 import (
 	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing/tracingtest"
+	"os"
+	"path/filepath"
 	"testing"
 
-	"github.com/oschwald/geoip2-golang"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
 )
 
 func TestGetCountryByIP_InvalidIP(t *testing.T) {
@@ -24,17 +30,35 @@ func TestGetCountryByIP_InvalidIP(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			country, err := repo.GetCountryByIP(ctx, tt.ip)
+			location, err := repo.GetCountryByIP(ctx, tt.ip)
 			assert.Error(t, err)
-			assert.Empty(t, country)
+			assert.Nil(t, location)
 			assert.Contains(t, err.Error(), "Invalid IP address")
 		})
 	}
 }
 
+func TestGetCountryByIP_Tracing(t *testing.T) {
+	repo := NewIPVerifierRepo(nil)
+	ctx, parent := tracingtest.NewParent(t.Context())
+
+	_, err := repo.GetCountryByIP(ctx, "not-an-ip")
+	require.Error(t, err)
+
+	spans := tracingtest.Spans(parent.TraceID())
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "IPVerifierRepo.GetCountryByIP", span.Name())
+	assert.Equal(t, parent.SpanID(), span.Parent().SpanID())
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, "exception", span.Events()[0].Name)
+	// An invalid IP is the caller's error, not a failed lookup
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
 func TestGetCountryByIP_ValidIP_WithRealDatabase(t *testing.T) {
 	// This test requires the actual GeoLite2-Country.mmdb file
-	db, err := geoip2.Open("../../data/GeoLite2-Country.mmdb")
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
 	if err != nil {
 		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
 		return
@@ -45,25 +69,167 @@ func TestGetCountryByIP_ValidIP_WithRealDatabase(t *testing.T) {
 	ctx := context.Background()
 
 	tests := []struct {
-		name            string
-		ip              string
-		expectedCountry string
+		name               string
+		ip                 string
+		expectedCountry    string
+		expectedContinent  string
+		expectedRegistered string
 	}{
-		{"Google DNS US", "8.8.8.8", "US"},
-		{"Google DNS IPv6", "2001:4860:4860::8888", "US"},
+		{"Google DNS US", "8.8.8.8", "US", "NA", "US"},
+		{"Google DNS IPv6", "2001:4860:4860::8888", "US", "NA", "US"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			country, err := repo.GetCountryByIP(ctx, tt.ip)
-			assert.NoError(t, err)
-			assert.Equal(t, tt.expectedCountry, country)
+			location, err := repo.GetCountryByIP(ctx, tt.ip)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCountry, location.Country)
+			assert.Equal(t, tt.expectedContinent, location.Continent)
+			assert.Equal(t, tt.expectedRegistered, location.RegisteredCountry)
+			assert.Equal(t, db.BuildEpoch(), location.DatabaseBuild)
 		})
 	}
 }
 
+func TestGetCountryByIP_NoCountry_WithRealDatabase(t *testing.T) {
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+		return
+	}
+	defer db.Close()
+
+	repo := NewIPVerifierRepo(db)
+	ctx := context.Background()
+
+	for _, ip := range []string{"10.0.0.5", "127.0.0.1", "::1"} {
+		t.Run(ip, func(t *testing.T) {
+			location, err := repo.GetCountryByIP(ctx, ip)
+			assert.Nil(t, location)
+			assert.True(t, apperrors.IsNotFoundError(err))
+		})
+	}
+}
+
+func TestGetCountryByIP_WithCityDatabase(t *testing.T) {
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+		return
+	}
+	defer db.Close()
+
+	city, err := OpenDatabase("../../data/GeoLite2-City.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-City.mmdb not found")
+		return
+	}
+	defer city.Close()
+
+	repo := NewIPVerifierRepo(db, WithCityDatabase(city))
+	ctx := context.Background()
+
+	location, err := repo.GetCountryByIP(ctx, "8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", location.Country)
+	assert.Equal(t, "NA", location.Continent)
+	assert.NotEmpty(t, location.Subdivisions)
+	require.NotNil(t, location.Coordinates)
+
+	assert.NoError(t, repo.HealthCheck(ctx))
+}
+
+func TestGetASNByIP_WithASNDatabase(t *testing.T) {
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+		return
+	}
+	defer db.Close()
+
+	asnDB, err := OpenDatabase("../../data/GeoLite2-ASN.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-ASN.mmdb not found")
+		return
+	}
+	defer asnDB.Close()
+
+	repo := NewIPVerifierRepo(db, WithASNDatabase(asnDB))
+	ctx := context.Background()
+
+	asn, err := repo.GetASNByIP(ctx, "8.8.8.8")
+	require.NoError(t, err)
+	require.NotNil(t, asn)
+	assert.Equal(t, uint(15169), asn.Number)
+	assert.NotEmpty(t, asn.Organization)
+
+	asn, err = repo.GetASNByIP(ctx, "10.0.0.5")
+	require.NoError(t, err)
+	assert.Nil(t, asn)
+
+	assert.NoError(t, repo.HealthCheck(ctx))
+}
+
+func TestGetASNByIP_WithoutASNDatabase(t *testing.T) {
+	repo := NewIPVerifierRepo(nil)
+	ctx := context.Background()
+
+	asn, err := repo.GetASNByIP(ctx, "8.8.8.8")
+	require.NoError(t, err)
+	assert.Nil(t, asn)
+
+	_, err = repo.GetASNByIP(ctx, "not-an-ip")
+	assert.Error(t, err)
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
+func TestGetAnonymityByIP_WithAnonymousIPDatabase(t *testing.T) {
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+		return
+	}
+	defer db.Close()
+
+	anonymous, err := OpenDatabase("../../data/GeoIP2-Anonymous-IP.mmdb")
+	if err != nil {
+		t.Skip("Skipping test: GeoIP2-Anonymous-IP.mmdb not found")
+		return
+	}
+	defer anonymous.Close()
+
+	torPath := filepath.Join(t.TempDir(), "tor-exits.txt")
+	require.NoError(t, os.WriteFile(torPath, []byte("8.8.4.4\n"), 0o644))
+	tor, err := OpenTorExitList(torPath)
+	require.NoError(t, err)
+
+	repo := NewIPVerifierRepo(db, WithAnonymousIPDatabase(anonymous), WithTorExitList(tor))
+	ctx := context.Background()
+
+	anonymity, err := repo.GetAnonymityByIP(ctx, "8.8.4.4")
+	require.NoError(t, err)
+	require.NotNil(t, anonymity)
+	assert.True(t, anonymity.IsTor)
+	assert.True(t, anonymity.IsAnonymous)
+
+	anonymity, err = repo.GetAnonymityByIP(ctx, "8.8.8.8")
+	require.NoError(t, err)
+	require.NotNil(t, anonymity)
+	assert.Equal(t, domain.Anonymity{}, *anonymity)
+
+	assert.NoError(t, repo.HealthCheck(ctx))
+}
+
+func TestGetAnonymityByIP_WithoutSources(t *testing.T) {
+	repo := NewIPVerifierRepo(nil)
+
+	anonymity, err := repo.GetAnonymityByIP(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Nil(t, anonymity)
+}
+
 func TestHealthCheck(t *testing.T) {
-	db, err := geoip2.Open("../../data/GeoLite2-Country.mmdb")
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
 	if err != nil {
 		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
 		return
@@ -78,7 +244,7 @@ func TestHealthCheck(t *testing.T) {
 }
 
 func TestNewIPVerifierRepo(t *testing.T) {
-	db, err := geoip2.Open("../../data/GeoLite2-Country.mmdb")
+	db, err := OpenDatabase("../../data/GeoLite2-Country.mmdb")
 	if err != nil {
 		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
 		return
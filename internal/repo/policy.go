@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+)
+
+type PolicyRepo struct {
+	mu       sync.RWMutex
+	policies map[string]domain.Policy
+}
+
+// NewPolicyRepo creates an in-memory PolicyRepo that implements domain.PolicyRepo
+func NewPolicyRepo() domain.PolicyRepo {
+	return &PolicyRepo{
+		policies: make(map[string]domain.Policy),
+	}
+}
+
+// List returns all policies ordered by ID
+func (r *PolicyRepo) List(ctx context.Context) ([]domain.Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policies := make([]domain.Policy, 0, len(r.policies))
+	for _, policy := range r.policies {
+		policies = append(policies, clonePolicy(policy))
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].ID < policies[j].ID
+	})
+	return policies, nil
+}
+
+// Get retrieves a policy by ID
+func (r *PolicyRepo) Get(ctx context.Context, id string) (*domain.Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, ok := r.policies[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("Policy %q not found", id), nil)
+	}
+	policy = clonePolicy(policy)
+	return &policy, nil
+}
+
+// Create stores a new policy
+func (r *PolicyRepo) Create(ctx context.Context, policy domain.Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[policy.ID]; ok {
+		return apperrors.NewConflictError(fmt.Sprintf("Policy %q already exists", policy.ID), nil)
+	}
+	r.policies[policy.ID] = clonePolicy(policy)
+	return nil
+}
+
+// Update replaces an existing policy
+func (r *PolicyRepo) Update(ctx context.Context, policy domain.Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[policy.ID]; !ok {
+		return apperrors.NewNotFoundError(fmt.Sprintf("Policy %q not found", policy.ID), nil)
+	}
+	r.policies[policy.ID] = clonePolicy(policy)
+	return nil
+}
+
+// Delete removes a policy by ID
+func (r *PolicyRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[id]; !ok {
+		return apperrors.NewNotFoundError(fmt.Sprintf("Policy %q not found", id), nil)
+	}
+	delete(r.policies, id)
+	return nil
+}
+
+// clonePolicy copies the slices so callers cannot mutate stored policies
+func clonePolicy(policy domain.Policy) domain.Policy {
+	policy.AllowedCountries = slices.Clone(policy.AllowedCountries)
+	policy.DeniedCountries = slices.Clone(policy.DeniedCountries)
+	policy.AllowedASNs = slices.Clone(policy.AllowedASNs)
+	policy.DeniedASNs = slices.Clone(policy.DeniedASNs)
+	policy.AllowedCIDRs = slices.Clone(policy.AllowedCIDRs)
+	policy.DeniedCIDRs = slices.Clone(policy.DeniedCIDRs)
+	policy.DeniedAnonymity = slices.Clone(policy.DeniedAnonymity)
+	return policy
+}
+
+// policyFile is the on-disk format of the policy configuration file
+type policyFile struct {
+	Defaults policyDocument   `json:"defaults"`
+	Policies []policyDocument `json:"policies"`
+}
+
+type policyDocument struct {
+	ID               string                 `json:"id"`
+	Description      string                 `json:"description"`
+	AllowedCountries []string               `json:"allowed_countries"`
+	DeniedCountries  []string               `json:"denied_countries"`
+	AllowedASNs      []uint                 `json:"allowed_asns"`
+	DeniedASNs       []uint                 `json:"denied_asns"`
+	AllowedCIDRs     []string               `json:"allowed_cidrs"`
+	DeniedCIDRs      []string               `json:"denied_cidrs"`
+	DeniedAnonymity  []domain.AnonymityType `json:"denied_anonymity"`
+	UnknownAction    string                 `json:"unknown_action"`
+	AllowInternal    *bool                  `json:"allow_internal"`
+	MatchMode        string                 `json:"match_mode"`
+}
+
+// LoadPolicyFile reads policies from a JSON file. Fields a policy omits are
+// taken from the file's "defaults" block; an explicit empty list overrides
+// the default.
+func LoadPolicyFile(path string) ([]domain.Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file policyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	policies := make([]domain.Policy, 0, len(file.Policies))
+	for _, doc := range file.Policies {
+		if doc.AllowedCountries == nil {
+			doc.AllowedCountries = file.Defaults.AllowedCountries
+		}
+		if doc.DeniedCountries == nil {
+			doc.DeniedCountries = file.Defaults.DeniedCountries
+		}
+		if doc.AllowedASNs == nil {
+			doc.AllowedASNs = file.Defaults.AllowedASNs
+		}
+		if doc.DeniedASNs == nil {
+			doc.DeniedASNs = file.Defaults.DeniedASNs
+		}
+		if doc.AllowedCIDRs == nil {
+			doc.AllowedCIDRs = file.Defaults.AllowedCIDRs
+		}
+		if doc.DeniedCIDRs == nil {
+			doc.DeniedCIDRs = file.Defaults.DeniedCIDRs
+		}
+		if doc.DeniedAnonymity == nil {
+			doc.DeniedAnonymity = file.Defaults.DeniedAnonymity
+		}
+		if doc.UnknownAction == "" {
+			doc.UnknownAction = file.Defaults.UnknownAction
+		}
+		if doc.AllowInternal == nil {
+			doc.AllowInternal = file.Defaults.AllowInternal
+		}
+		if doc.MatchMode == "" {
+			doc.MatchMode = file.Defaults.MatchMode
+		}
+		policies = append(policies, domain.Policy{
+			ID:               doc.ID,
+			Description:      doc.Description,
+			AllowedCountries: slices.Clone(doc.AllowedCountries),
+			DeniedCountries:  slices.Clone(doc.DeniedCountries),
+			AllowedASNs:      slices.Clone(doc.AllowedASNs),
+			DeniedASNs:       slices.Clone(doc.DeniedASNs),
+			AllowedCIDRs:     slices.Clone(doc.AllowedCIDRs),
+			DeniedCIDRs:      slices.Clone(doc.DeniedCIDRs),
+			DeniedAnonymity:  slices.Clone(doc.DeniedAnonymity),
+			UnknownAction:    domain.UnknownAction(doc.UnknownAction),
+			AllowInternal:    doc.AllowInternal != nil && *doc.AllowInternal,
+			MatchMode:        domain.MatchMode(doc.MatchMode),
+		})
+	}
+	return policies, nil
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyRepo_CRUD(t *testing.T) {
+	repo := NewPolicyRepo()
+	ctx := context.Background()
+
+	policy := domain.Policy{ID: "sanctions", DeniedCountries: []string{"KP"}}
+	require.NoError(t, repo.Create(ctx, policy))
+
+	err := repo.Create(ctx, policy)
+	assert.True(t, apperrors.IsConflictError(err))
+
+	got, err := repo.Get(ctx, "sanctions")
+	require.NoError(t, err)
+	assert.Equal(t, policy, *got)
+
+	policy.DeniedCountries = []string{"KP", "IR"}
+	require.NoError(t, repo.Update(ctx, policy))
+
+	got, err = repo.Get(ctx, "sanctions")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"KP", "IR"}, got.DeniedCountries)
+
+	require.NoError(t, repo.Delete(ctx, "sanctions"))
+
+	_, err = repo.Get(ctx, "sanctions")
+	assert.True(t, apperrors.IsNotFoundError(err))
+	assert.True(t, apperrors.IsNotFoundError(repo.Update(ctx, policy)))
+	assert.True(t, apperrors.IsNotFoundError(repo.Delete(ctx, "sanctions")))
+}
+
+func TestPolicyRepo_ListSortedAndIsolated(t *testing.T) {
+	repo := NewPolicyRepo()
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, domain.Policy{ID: "b", AllowedCountries: []string{"US"}}))
+	require.NoError(t, repo.Create(ctx, domain.Policy{ID: "a", AllowedCountries: []string{"CA"}}))
+
+	policies, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "a", policies[0].ID)
+	assert.Equal(t, "b", policies[1].ID)
+
+	// Mutating a returned policy must not change the stored one
+	policies[0].AllowedCountries[0] = "RU"
+	got, err := repo.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CA"}, got.AllowedCountries)
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"defaults": {"denied_countries": ["KP", "IR"], "unknown_action": "allow", "allow_internal": true, "match_mode": "all", "denied_asns": [64500]},
+		"policies": [
+			{"id": "north-america", "description": "US and Canada", "allowed_countries": ["US", "CA"], "allowed_asns": [15169]},
+			{"id": "open", "denied_countries": [], "unknown_action": "error", "allow_internal": false}
+		]
+	}`), 0o644))
+
+	policies, err := LoadPolicyFile(path)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+
+	assert.Equal(t, domain.Policy{
+		ID:               "north-america",
+		Description:      "US and Canada",
+		AllowedCountries: []string{"US", "CA"},
+		DeniedCountries:  []string{"KP", "IR"},
+		AllowedASNs:      []uint{15169},
+		DeniedASNs:       []uint{64500},
+		UnknownAction:    domain.UnknownActionAllow,
+		AllowInternal:    true,
+		MatchMode:        domain.MatchAll,
+	}, policies[0])
+
+	// An explicit empty list overrides the default
+	assert.Equal(t, "open", policies[1].ID)
+	assert.Empty(t, policies[1].DeniedCountries)
+	assert.Equal(t, domain.UnknownActionError, policies[1].UnknownAction)
+	assert.False(t, policies[1].AllowInternal)
+}
+
+func TestLoadPolicyFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"policies": [`), 0o644))
+
+	_, err := LoadPolicyFile(path)
+	assert.Error(t, err)
+
+	_, err = LoadPolicyFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TorExitList is a hot-reloadable set of Tor exit node addresses loaded from
+// a local file. The file may be the plain bulk exit list (one address per
+// line) or the exit-addresses format, from which the ExitAddress lines are
+// used. Blank lines and lines starting with '#' are ignored.
+type TorExitList struct {
+	path string
+
+	// reloadMu serialises reloads triggered by the watcher and by SIGHUP
+	reloadMu sync.Mutex
+
+	mu      sync.RWMutex
+	addrs   map[netip.Addr]struct{}
+	modTime time.Time
+	size    int64
+}
+
+// OpenTorExitList loads the Tor exit node list at path
+func OpenTorExitList(path string) (*TorExitList, error) {
+	l := &TorExitList{path: path}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path returns the file the list was loaded from
+func (l *TorExitList) Path() string {
+	return l.path
+}
+
+// Len returns the number of exit node addresses in the list
+func (l *TorExitList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.addrs)
+}
+
+// Contains reports whether addr is a listed exit node
+func (l *TorExitList) Contains(addr netip.Addr) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.addrs[addr.Unmap().WithZone("")]
+	return ok
+}
+
+// Reload reads the file again and swaps in the new list. On any error the
+// current list is left in place.
+func (l *TorExitList) Reload() error {
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+
+	old := l.Len()
+	if err := l.load(); err != nil {
+		return err
+	}
+
+	slog.Info("Tor exit list reloaded",
+		"path", l.path,
+		"old_count", old,
+		"new_count", l.Len(),
+	)
+	return nil
+}
+
+// Watch polls the file every interval and reloads it when its modification
+// time or size changes. It blocks until ctx is cancelled.
+func (l *TorExitList) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !l.changed() {
+				continue
+			}
+			if err := l.Reload(); err != nil {
+				slog.Error("Failed to reload Tor exit list", "error", err, "path", l.path)
+			}
+		}
+	}
+}
+
+// load parses the file and replaces the current list
+func (l *TorExitList) load() error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", l.path, err)
+	}
+
+	addrs := make(map[netip.Addr]struct{})
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var entry string
+		switch {
+		case fields[0] == "ExitAddress" && len(fields) > 1:
+			entry = fields[1]
+		case len(fields) == 1:
+			entry = fields[0]
+		default:
+			// Other exit-addresses keys (ExitNode, Published, LastStatus)
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return fmt.Errorf("%s:%d: invalid address %q", l.path, line, entry)
+		}
+		addrs[addr.Unmap()] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", l.path, err)
+	}
+
+	l.mu.Lock()
+	l.addrs = addrs
+	l.modTime = info.ModTime()
+	l.size = info.Size()
+	l.mu.Unlock()
+	return nil
+}
+
+// changed reports whether the file on disk differs from the loaded one
+func (l *TorExitList) changed() bool {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return !info.ModTime().Equal(l.modTime) || info.Size() != l.size
+}
@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenTorExitList_Formats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tor-exits.txt")
+	require.NoError(t, os.WriteFile(path, []byte(`# bulk exit list
+185.220.101.1
+
+2001:db8::1
+ExitNode 0011BD2485AD45D984EC4159C88FC066E5E3300E
+Published 2024-01-01 00:00:00
+LastStatus 2024-01-01 01:00:00
+ExitAddress 185.220.101.2 2024-01-01 01:00:00
+`), 0o644))
+
+	list, err := OpenTorExitList(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, list.Len())
+
+	assert.True(t, list.Contains(netip.MustParseAddr("185.220.101.1")))
+	assert.True(t, list.Contains(netip.MustParseAddr("185.220.101.2")))
+	assert.True(t, list.Contains(netip.MustParseAddr("::ffff:185.220.101.1")))
+	assert.True(t, list.Contains(netip.MustParseAddr("2001:db8::1")))
+	assert.False(t, list.Contains(netip.MustParseAddr("185.220.101.3")))
+}
+
+func TestOpenTorExitList_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := OpenTorExitList(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "invalid.txt")
+	require.NoError(t, os.WriteFile(path, []byte("185.220.101.1\nnot-an-ip\n"), 0o644))
+	_, err = OpenTorExitList(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ":2: invalid address")
+}
+
+func TestTorExitList_NilContains(t *testing.T) {
+	var list *TorExitList
+	assert.False(t, list.Contains(netip.MustParseAddr("185.220.101.1")))
+}
+
+func TestTorExitList_ReloadKeepsListOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tor-exits.txt")
+	replaceFile(t, path, []byte("185.220.101.1\n"))
+
+	list, err := OpenTorExitList(path)
+	require.NoError(t, err)
+
+	replaceFile(t, path, []byte("garbage\n"))
+	assert.Error(t, list.Reload())
+	assert.True(t, list.Contains(netip.MustParseAddr("185.220.101.1")))
+
+	replaceFile(t, path, []byte("185.220.101.2\n"))
+	require.NoError(t, list.Reload())
+	assert.False(t, list.Contains(netip.MustParseAddr("185.220.101.1")))
+	assert.True(t, list.Contains(netip.MustParseAddr("185.220.101.2")))
+}
+
+func TestTorExitList_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tor-exits.txt")
+	replaceFile(t, path, []byte("185.220.101.1\n"))
+
+	list, err := OpenTorExitList(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go list.Watch(ctx, 10*time.Millisecond)
+
+	replaceFile(t, path, []byte("185.220.101.1\n185.220.101.2\n"))
+
+	assert.Eventually(t, func() bool {
+		return list.Contains(netip.MustParseAddr("185.220.101.2"))
+	}, 2*time.Second, 10*time.Millisecond)
+}
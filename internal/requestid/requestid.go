@@ -0,0 +1,117 @@
+// Package requestid carries the ID of an HTTP request in its context, so the
+// logs, error responses and response headers of a request can be correlated.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"slices"
+)
+
+// Header is the request and response header carrying the request ID
+const Header = "X-Request-ID"
+
+// maxLength is the longest request ID accepted from a caller
+const maxLength = 128
+
+type contextKey struct{}
+
+// New generates a random request ID
+func New() string {
+	return rand.Text()
+}
+
+// Valid reports whether an ID supplied by a caller can be used as is: it must
+// be 1 to 128 printable ASCII characters without spaces, so it is safe to
+// echo in a header and to search for in logs
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID of ctx, or "" when it has none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// logHandler adds the request ID of the record's context to every record, at
+// the top level even when the logger has groups
+type logHandler struct {
+	slog.Handler
+	// root is the wrapped handler before the first group was opened, and
+	// scoped replays the groups and attributes added since then; both are
+	// nil while the logger has no groups
+	root   slog.Handler
+	scoped []func(slog.Handler) slog.Handler
+}
+
+// NewLogHandler wraps next so that records logged with a request context
+// (e.g., slog.InfoContext(ctx, ...)) carry a request_id attribute
+func NewLogHandler(next slog.Handler) slog.Handler {
+	return &logHandler{Handler: next}
+}
+
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	id := FromContext(ctx)
+	if id == "" {
+		return h.Handler.Handle(ctx, r)
+	}
+	if h.root == nil {
+		r = r.Clone()
+		r.AddAttrs(slog.String("request_id", id))
+		return h.Handler.Handle(ctx, r)
+	}
+
+	// Attributes of the record belong to the innermost group, so the ID is
+	// added before the groups are opened again
+	next := h.root.WithAttrs([]slog.Attr{slog.String("request_id", id)})
+	for _, apply := range h.scoped {
+		next = apply(next)
+	}
+	return next.Handle(ctx, r)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if h.root == nil {
+		return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+	}
+	return h.with(h.Handler.WithAttrs(attrs), func(next slog.Handler) slog.Handler {
+		return next.WithAttrs(attrs)
+	})
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.Handler.WithGroup(name), func(next slog.Handler) slog.Handler {
+		return next.WithGroup(name)
+	})
+}
+
+// with returns a handler scoped by apply, which next already has applied
+func (h *logHandler) with(next slog.Handler, apply func(slog.Handler) slog.Handler) *logHandler {
+	root := h.root
+	if root == nil {
+		root = h.Handler
+	}
+	return &logHandler{
+		Handler: next,
+		root:    root,
+		scoped:  append(slices.Clip(h.scoped), apply),
+	}
+}
@@ -0,0 +1,88 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{"uuid", "3f2b8c1e-9d4a-4e6b-8f0a-2c5d7e9b1a3f", true},
+		{"envoy style", "req_01HZX:abc.def/1", true},
+		{"empty", "", false},
+		{"space", "abc def", false},
+		{"newline", "abc\ndef", false},
+		{"non-ASCII", "abcé", false},
+		{"longest", strings.Repeat("a", 128), true},
+		{"too long", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, Valid(tt.id))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.True(t, Valid(id))
+	assert.NotEqual(t, id, New())
+}
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "abc", FromContext(NewContext(context.Background(), "abc")))
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+	ctx := NewContext(context.Background(), "abc")
+
+	entry := func() map[string]any {
+		t.Helper()
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		buf.Reset()
+		return entry
+	}
+
+	logger.InfoContext(ctx, "with request")
+	assert.Equal(t, "abc", entry()["request_id"])
+
+	logger.Info("without request")
+	assert.NotContains(t, entry(), "request_id")
+
+	logger.With("component", "repo").InfoContext(ctx, "with attributes")
+	e := entry()
+	assert.Equal(t, "abc", e["request_id"])
+	assert.Equal(t, "repo", e["component"])
+
+	logger.With("component", "repo").WithGroup("lookup").With("attempt", 1).InfoContext(ctx, "in group", "database", "country")
+	e = entry()
+	assert.Equal(t, "abc", e["request_id"])
+	assert.Equal(t, "repo", e["component"])
+	assert.Equal(t, map[string]any{"attempt": float64(1), "database": "country"}, e["lookup"])
+
+	logger.WithGroup("lookup").WithGroup("city").InfoContext(ctx, "in nested group", "found", true)
+	e = entry()
+	assert.Equal(t, "abc", e["request_id"])
+	assert.Equal(t, map[string]any{"city": map[string]any{"found": true}}, e["lookup"])
+
+	logger.WithGroup("lookup").Info("grouped without request", "database", "country")
+	e = entry()
+	assert.NotContains(t, e, "request_id")
+	assert.Equal(t, map[string]any{"database": "country"}, e["lookup"])
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"math/bits"
+	"net/netip"
+	"strings"
+)
+
+// cidrOverride is a CIDR entry of a policy's override lists
+type cidrOverride struct {
+	prefix  netip.Prefix
+	allowed bool
+}
+
+// cidrOverrides holds a policy's allowed and denied CIDRs for longest-prefix
+// lookups. IPv4 and IPv6 prefixes live in separate tries.
+type cidrOverrides struct {
+	v4 *trieNode
+	v6 *trieNode
+}
+
+// trieNode is a node of a path-compressed binary (patricia) trie. Each node
+// covers prefix; children are indexed by the first bit after it. Nodes that
+// only join two branches carry no override.
+type trieNode struct {
+	prefix   netip.Prefix
+	override *cidrOverride
+	children [2]*trieNode
+}
+
+// newCIDROverrides parses the policy's CIDR lists. It returns nil when the
+// policy has none. When the same prefix is both allowed and denied, the deny
+// entry wins.
+func newCIDROverrides(policy domain.Policy) (*cidrOverrides, error) {
+	if len(policy.AllowedCIDRs) == 0 && len(policy.DeniedCIDRs) == 0 {
+		return nil, nil
+	}
+
+	overrides := &cidrOverrides{}
+	lists := []struct {
+		field   string
+		entries []string
+		allowed bool
+	}{
+		{"allowed_cidrs", policy.AllowedCIDRs, true},
+		{"denied_cidrs", policy.DeniedCIDRs, false},
+	}
+	for _, list := range lists {
+		for _, entry := range list.entries {
+			prefix, err := parseCIDR(list.field, entry)
+			if err != nil {
+				return nil, err
+			}
+			overrides.insert(&cidrOverride{prefix: prefix, allowed: list.allowed})
+		}
+	}
+	return overrides, nil
+}
+
+// parseCIDR parses a CIDR list entry. A bare address is treated as a single
+// host prefix; host bits are masked off.
+func parseCIDR(field, entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+
+	var prefix netip.Prefix
+	if strings.Contains(entry, "/") {
+		p, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, apperrors.NewValidationError(
+				fmt.Sprintf("%s contains invalid CIDR %q", field, entry), nil)
+		}
+		prefix = p
+	} else {
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return netip.Prefix{}, apperrors.NewValidationError(
+				fmt.Sprintf("%s contains invalid CIDR %q", field, entry), nil)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	// IPv4-mapped IPv6 prefixes are matched against plain IPv4 addresses
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// insert adds an override to the trie for its address family
+func (o *cidrOverrides) insert(override *cidrOverride) {
+	if override.prefix.Addr().Is4() {
+		insertNode(&o.v4, override)
+	} else {
+		insertNode(&o.v6, override)
+	}
+}
+
+// lookup returns the most specific override covering addr, if any
+func (o *cidrOverrides) lookup(addr netip.Addr) (*cidrOverride, bool) {
+	if o == nil {
+		return nil, false
+	}
+
+	addr = addr.Unmap().WithZone("")
+	n := o.v6
+	if addr.Is4() {
+		n = o.v4
+	}
+
+	var best *cidrOverride
+	for n != nil && n.prefix.Contains(addr) {
+		if n.override != nil {
+			best = n.override
+		}
+		if n.prefix.Bits() == addr.BitLen() {
+			break
+		}
+		n = n.children[bitAt(addr, n.prefix.Bits())]
+	}
+	return best, best != nil
+}
+
+// insertNode places override below *n, splitting compressed paths as needed
+func insertNode(n **trieNode, override *cidrOverride) {
+	prefix := override.prefix
+	for {
+		cur := *n
+		if cur == nil {
+			*n = &trieNode{prefix: prefix, override: override}
+			return
+		}
+
+		common := commonBits(cur.prefix, prefix)
+		switch {
+		case common == cur.prefix.Bits() && common == prefix.Bits():
+			// Same prefix: a deny entry is never replaced by an allow entry
+			if cur.override == nil || !override.allowed {
+				cur.override = override
+			}
+			return
+
+		case common == cur.prefix.Bits():
+			// The new prefix is more specific; descend
+			n = &cur.children[bitAt(prefix.Addr(), common)]
+
+		case common == prefix.Bits():
+			// The new prefix covers the current node
+			node := &trieNode{prefix: prefix, override: override}
+			node.children[bitAt(cur.prefix.Addr(), common)] = cur
+			*n = node
+			return
+
+		default:
+			// The prefixes diverge; join them under their common prefix
+			join := &trieNode{prefix: netip.PrefixFrom(prefix.Addr(), common).Masked()}
+			join.children[bitAt(cur.prefix.Addr(), common)] = cur
+			join.children[bitAt(prefix.Addr(), common)] = &trieNode{prefix: prefix, override: override}
+			*n = join
+			return
+		}
+	}
+}
+
+// commonBits returns the length of the longest prefix shared by a and b
+func commonBits(a, b netip.Prefix) int {
+	limit := min(a.Bits(), b.Bits())
+	ab, bb := a.Addr().AsSlice(), b.Addr().AsSlice()
+
+	common := 0
+	for i := range ab {
+		if x := ab[i] ^ bb[i]; x != 0 {
+			common += bits.LeadingZeros8(x)
+			break
+		}
+		common += 8
+	}
+	return min(common, limit)
+}
+
+// bitAt returns bit i of addr, counting from the most significant bit
+func bitAt(addr netip.Addr, i int) int {
+	b := addr.AsSlice()
+	return int(b[i/8]>>(7-i%8)) & 1
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"math/rand"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIDROverrides_Lookup(t *testing.T) {
+	overrides, err := newCIDROverrides(domain.Policy{
+		AllowedCIDRs: []string{"10.0.0.0/8", "192.0.2.0/24", "2001:db8::/32", "198.51.100.7"},
+		DeniedCIDRs:  []string{"10.1.0.0/16", "10.1.2.0/24", "2001:db8:bad::/48", "192.0.2.0/24"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		ip      string
+		matched bool
+		allowed bool
+		prefix  string
+	}{
+		{"10.9.9.9", true, true, "10.0.0.0/8"},
+		{"10.1.9.9", true, false, "10.1.0.0/16"},
+		{"10.1.2.3", true, false, "10.1.2.0/24"},
+		{"11.0.0.1", false, false, ""},
+		{"192.0.2.1", true, false, "192.0.2.0/24"},
+		{"198.51.100.7", true, true, "198.51.100.7/32"},
+		{"198.51.100.8", false, false, ""},
+		{"::ffff:10.9.9.9", true, true, "10.0.0.0/8"},
+		{"2001:db8::1", true, true, "2001:db8::/32"},
+		{"2001:db8:bad::1", true, false, "2001:db8:bad::/48"},
+		{"2001:db9::1", false, false, ""},
+		{"fe80::1%eth0", false, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			override, ok := overrides.lookup(netip.MustParseAddr(tt.ip))
+			assert.Equal(t, tt.matched, ok)
+			if ok {
+				assert.Equal(t, tt.allowed, override.allowed)
+				assert.Equal(t, tt.prefix, override.prefix.String())
+			}
+		})
+	}
+}
+
+func TestCIDROverrides_NoLists(t *testing.T) {
+	overrides, err := newCIDROverrides(domain.Policy{AllowedCountries: []string{"US"}})
+	require.NoError(t, err)
+	assert.Nil(t, overrides)
+
+	_, ok := overrides.lookup(netip.MustParseAddr("8.8.8.8"))
+	assert.False(t, ok)
+}
+
+func TestCIDROverrides_InvalidEntries(t *testing.T) {
+	for _, entry := range []string{"10.0.0.0/33", "not-a-cidr", "2001:db8::/129", ""} {
+		t.Run(entry, func(t *testing.T) {
+			_, err := newCIDROverrides(domain.Policy{DeniedCIDRs: []string{entry}})
+			require.Error(t, err)
+			assert.True(t, apperrors.IsValidationError(err))
+			assert.Contains(t, err.Error(), "denied_cidrs contains invalid CIDR")
+		})
+	}
+}
+
+func TestParseCIDR_Canonical(t *testing.T) {
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"10.1.2.3/8", "10.0.0.0/8"},
+		{" 192.0.2.1 ", "192.0.2.1/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+		{"::ffff:10.0.0.0/104", "10.0.0.0/8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			prefix, err := parseCIDR("allowed_cidrs", tt.entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, prefix.String())
+		})
+	}
+}
+
+// TestCIDROverrides_MatchesLinearScan compares the trie with a brute-force
+// longest-prefix search over random IPv4 prefixes
+func TestCIDROverrides_MatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	randomAddr := func() netip.Addr {
+		// Keep addresses in a small space so prefixes overlap often
+		return netip.AddrFrom4([4]byte{10, byte(rng.Intn(4)), byte(rng.Intn(256)), byte(rng.Intn(256))})
+	}
+
+	var allowed, denied []string
+	for i := 0; i < 500; i++ {
+		prefix := netip.PrefixFrom(randomAddr(), 8+rng.Intn(25)).Masked().String()
+		if rng.Intn(2) == 0 {
+			allowed = append(allowed, prefix)
+		} else {
+			denied = append(denied, prefix)
+		}
+	}
+
+	overrides, err := newCIDROverrides(domain.Policy{AllowedCIDRs: allowed, DeniedCIDRs: denied})
+	require.NoError(t, err)
+
+	linear := func(addr netip.Addr) (int, bool, bool) {
+		bestBits, found, isAllowed := -1, false, false
+		for _, list := range []struct {
+			entries []string
+			allowed bool
+		}{{allowed, true}, {denied, false}} {
+			for _, entry := range list.entries {
+				prefix := netip.MustParsePrefix(entry)
+				if !prefix.Contains(addr) {
+					continue
+				}
+				// Deny wins over allow for identical prefixes
+				if prefix.Bits() > bestBits || (prefix.Bits() == bestBits && !list.allowed) {
+					bestBits, found, isAllowed = prefix.Bits(), true, list.allowed
+				}
+			}
+		}
+		return bestBits, found, isAllowed
+	}
+
+	for i := 0; i < 2000; i++ {
+		addr := randomAddr()
+		bits, found, isAllowed := linear(addr)
+
+		override, ok := overrides.lookup(addr)
+		require.Equal(t, found, ok, fmt.Sprintf("lookup %s", addr))
+		if ok {
+			assert.Equal(t, bits, override.prefix.Bits(), "lookup %s", addr)
+			assert.Equal(t, isAllowed, override.allowed, "lookup %s", addr)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"slices"
+	"sync"
+)
+
+// CompiledPolicy holds a policy together with the parsed form of its lists,
+// so they are not parsed again for every IP verified against it.
+// Verification reads only from the compiled policy, never from the caller's.
+type CompiledPolicy struct {
+	policy    domain.Policy
+	allowed   []rule
+	denied    []rule
+	overrides *cidrOverrides
+}
+
+// compilePolicy validates a policy and parses its lists
+func compilePolicy(policy domain.Policy) (*CompiledPolicy, error) {
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+	allowed, err := parseRules("allowed_countries", policy.AllowedCountries)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := parseRules("denied_countries", policy.DeniedCountries)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := newCIDROverrides(policy)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledPolicy{policy: policy, allowed: allowed, denied: denied, overrides: overrides}, nil
+}
+
+// PolicyCompiler compiles the policies IPs are verified against. Named
+// policies are compiled once by the policy service when they are created or
+// updated and kept by ID; inline policies are compiled on every call.
+// Share one PolicyCompiler between the policy and IP verifier services.
+type PolicyCompiler struct {
+	noSubdivisions bool
+	mu             sync.RWMutex
+	named          map[string]*CompiledPolicy
+}
+
+// CompilerOption configures a PolicyCompiler
+type CompilerOption func(*PolicyCompiler)
+
+// WithoutSubdivisions rejects subdivision rules such as "US-CA". Use it when
+// no City database is configured: locations then carry no subdivisions and
+// the rules would never match.
+func WithoutSubdivisions() CompilerOption {
+	return func(c *PolicyCompiler) {
+		c.noSubdivisions = true
+	}
+}
+
+// NewPolicyCompiler creates a PolicyCompiler without named policies
+func NewPolicyCompiler(opts ...CompilerOption) *PolicyCompiler {
+	c := &PolicyCompiler{named: make(map[string]*CompiledPolicy)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// build compiles a policy and rejects rules the configured databases cannot
+// match
+func (c *PolicyCompiler) build(policy domain.Policy) (*CompiledPolicy, error) {
+	compiled, err := compilePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	if c.noSubdivisions {
+		lists := []struct {
+			field string
+			rules []rule
+		}{
+			{"allowed_countries", compiled.allowed},
+			{"denied_countries", compiled.denied},
+		}
+		for _, list := range lists {
+			for _, r := range list.rules {
+				if r.kind == ruleSubdivision {
+					return nil, apperrors.NewValidationError(fmt.Sprintf(
+						"%s contains subdivision %q, but subdivision rules need a City database", list.field, r.value), nil)
+				}
+			}
+		}
+	}
+	return compiled, nil
+}
+
+// Validate checks a policy the way verifying against it does
+func (c *PolicyCompiler) Validate(policy domain.Policy) error {
+	_, err := c.build(policy)
+	return err
+}
+
+// Compile validates and compiles a fixed policy once, for callers that verify
+// every IP against it with VerifyCompiled
+func (c *PolicyCompiler) Compile(policy domain.Policy) (*CompiledPolicy, error) {
+	return c.build(policy)
+}
+
+// compile returns the stored form of a named policy, or compiles the policy
+// when it is inline, was not stored by the policy service or differs from the
+// stored version (e.g., it was read before an update was stored)
+func (c *PolicyCompiler) compile(policy domain.Policy) (*CompiledPolicy, error) {
+	if policy.ID != "" {
+		c.mu.RLock()
+		compiled, ok := c.named[policy.ID]
+		c.mu.RUnlock()
+		if ok && samePolicy(compiled.policy, policy) {
+			return compiled, nil
+		}
+	}
+	return c.build(policy)
+}
+
+// samePolicy reports whether two policies have the same rules and settings
+func samePolicy(a, b domain.Policy) bool {
+	return a.UnknownAction == b.UnknownAction &&
+		a.AllowInternal == b.AllowInternal &&
+		a.MatchMode == b.MatchMode &&
+		slices.Equal(a.AllowedCountries, b.AllowedCountries) &&
+		slices.Equal(a.DeniedCountries, b.DeniedCountries) &&
+		slices.Equal(a.AllowedASNs, b.AllowedASNs) &&
+		slices.Equal(a.DeniedASNs, b.DeniedASNs) &&
+		slices.Equal(a.AllowedCIDRs, b.AllowedCIDRs) &&
+		slices.Equal(a.DeniedCIDRs, b.DeniedCIDRs) &&
+		slices.Equal(a.DeniedAnonymity, b.DeniedAnonymity)
+}
+
+// store keeps the compiled form of a named policy
+func (c *PolicyCompiler) store(id string, compiled *CompiledPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.named[id] = compiled
+}
+
+// remove forgets a deleted named policy
+func (c *PolicyCompiler) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.named, id)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCompiler_NamedPolicies(t *testing.T) {
+	ctx := context.Background()
+	compiler := NewPolicyCompiler()
+	var updateErr error
+	policyService := NewPolicyService(&MockPolicyRepo{
+		UpdateFunc: func(ctx context.Context, policy domain.Policy) error { return updateErr },
+	}, compiler)
+
+	policy, err := policyService.CreatePolicy(ctx, domain.Policy{ID: "office", AllowedCIDRs: []string{"198.51.100.0/24"}})
+	require.NoError(t, err)
+
+	// A stored policy is compiled once, not on every verification
+	first, err := compiler.compile(*policy)
+	require.NoError(t, err)
+	second, err := compiler.compile(*policy)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	updated, err := policyService.UpdatePolicy(ctx, domain.Policy{ID: "office", AllowedCIDRs: []string{"203.0.113.0/24"}})
+	require.NoError(t, err)
+	compiled, err := compiler.compile(*updated)
+	require.NoError(t, err)
+	assert.NotSame(t, first, compiled)
+	_, ok := compiled.overrides.lookup(netip.MustParseAddr("203.0.113.1"))
+	assert.True(t, ok)
+
+	// A failed update keeps the stored version
+	updateErr = errors.New("storage failure")
+	_, err = policyService.UpdatePolicy(ctx, domain.Policy{ID: "office", AllowedCIDRs: []string{"192.0.2.0/24"}})
+	require.Error(t, err)
+	kept, err := compiler.compile(*updated)
+	require.NoError(t, err)
+	assert.Same(t, compiled, kept)
+
+	require.NoError(t, policyService.DeletePolicy(ctx, "office"))
+	recompiled, err := compiler.compile(*updated)
+	require.NoError(t, err)
+	assert.NotSame(t, compiled, recompiled)
+}
+
+func TestPolicyCompiler_DifferentVersion(t *testing.T) {
+	ctx := context.Background()
+	compiler := NewPolicyCompiler()
+	policyService := NewPolicyService(&MockPolicyRepo{}, compiler)
+
+	read, err := policyService.CreatePolicy(ctx, domain.Policy{ID: "office", AllowedCIDRs: []string{"198.51.100.0/24"}})
+	require.NoError(t, err)
+	_, err = policyService.UpdatePolicy(ctx, domain.Policy{
+		ID:            "office",
+		AllowedCIDRs:  []string{"203.0.113.0/24"},
+		UnknownAction: domain.UnknownActionAllow,
+	})
+	require.NoError(t, err)
+
+	// A policy read before the update is verified as it was read, not with
+	// the updated lists
+	compiled, err := compiler.compile(*read)
+	require.NoError(t, err)
+	assert.Equal(t, *read, compiled.policy)
+	_, ok := compiled.overrides.lookup(netip.MustParseAddr("198.51.100.1"))
+	assert.True(t, ok)
+
+	// A caller's policy that shares the ID gets its own rules
+	ipService := NewIPVerifierService(&MockIPVerifierRepo{}, WithPolicyCompiler(compiler))
+	result, err := ipService.VerifyIP(ctx, "192.0.2.1", domain.Policy{ID: "office", DeniedCIDRs: []string{"192.0.2.0/24"}})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, domain.ReasonCIDRDenied, result.Reason)
+}
+
+func TestPolicyCompiler_InlinePolicies(t *testing.T) {
+	compiler := NewPolicyCompiler()
+	policy := domain.Policy{AllowedCIDRs: []string{"198.51.100.0/24"}}
+
+	first, err := compiler.compile(policy)
+	require.NoError(t, err)
+	second, err := compiler.compile(policy)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	_, err = compiler.compile(domain.Policy{AllowedCIDRs: []string{"10.0.0.0/40"}})
+	assert.Error(t, err)
+}
+
+func TestCompilePolicy_ParsesRules(t *testing.T) {
+	compiled, err := compilePolicy(domain.Policy{
+		AllowedCountries: []string{"us", "continent:eu", "group:Schengen"},
+		DeniedCountries:  []string{" us-ca "},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []rule{
+		{kind: ruleCountry, value: "US"},
+		{kind: ruleContinent, value: "EU"},
+		{kind: ruleGroup, value: "SCHENGEN"},
+	}, compiled.allowed)
+	assert.Equal(t, []rule{{kind: ruleSubdivision, value: "US-CA"}}, compiled.denied)
+
+	_, err = compilePolicy(domain.Policy{AllowedCountries: []string{"group:NATO"}})
+	assert.Error(t, err)
+}
+
+func TestPolicyCompiler_WithoutSubdivisions(t *testing.T) {
+	ctx := context.Background()
+	compiler := NewPolicyCompiler(WithoutSubdivisions())
+
+	// Named policies fail to load
+	policyService := NewPolicyService(&MockPolicyRepo{}, compiler)
+	_, err := policyService.CreatePolicy(ctx, domain.Policy{ID: "california", AllowedCountries: []string{"US-CA"}})
+	require.Error(t, err)
+	assert.True(t, apperrors.IsValidationError(err))
+	assert.Contains(t, err.Error(), `allowed_countries contains subdivision "US-CA", but subdivision rules need a City database`)
+
+	// Inline policies are rejected instead of never matching
+	ipService := NewIPVerifierService(&MockIPVerifierRepo{}, WithPolicyCompiler(compiler))
+	_, err = ipService.VerifyIP(ctx, "8.8.8.8", domain.Policy{AllowedCountries: []string{"US"}, DeniedCountries: []string{"us-ca"}})
+	require.Error(t, err)
+	assert.True(t, apperrors.IsValidationError(err))
+
+	assert.NoError(t, compiler.Validate(domain.Policy{AllowedCountries: []string{"US", "group:EU"}}))
+}
+
+func TestVerifyCompiled(t *testing.T) {
+	ctx := context.Background()
+	compiler := NewPolicyCompiler()
+	ipService := NewIPVerifierService(&MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			if ipAddress == "77.88.8.8" {
+				return &domain.Location{Country: "RU"}, nil
+			}
+			return &domain.Location{Country: "US"}, nil
+		},
+	}, WithPolicyCompiler(compiler))
+
+	policy, err := compiler.Compile(domain.Policy{AllowedCountries: []string{"US"}, DeniedCIDRs: []string{"198.51.100.0/24"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		ip      string
+		allowed bool
+		reason  domain.Reason
+	}{
+		{"8.8.8.8", true, domain.ReasonCountryAllowed},
+		{"77.88.8.8", false, domain.ReasonNotInAllowlist},
+		{"198.51.100.7", false, domain.ReasonCIDRDenied},
+	}
+	for _, tt := range tests {
+		result, err := ipService.VerifyCompiled(ctx, tt.ip, policy)
+		require.NoError(t, err)
+		assert.Equal(t, tt.allowed, result.Allowed, tt.ip)
+		assert.Equal(t, tt.reason, result.Reason, tt.ip)
+	}
+
+	_, err = compiler.Compile(domain.Policy{AllowedCountries: []string{"group:NATO"}})
+	assert.Error(t, err)
+}
@@ -2,52 +2,541 @@ package service
 
 import (
 	"context"
-	"ip-verifier/internal/domain"
-	apperrors "ip-verifier/internal/errors"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/KWilliams-dev/ip-verifier/internal/service")
+
+const (
+	// DefaultMaxBatchSize is the largest batch accepted by VerifyIPs unless overridden
+	DefaultMaxBatchSize = 1000
+	// DefaultBatchConcurrency is the number of lookups VerifyIPs runs in parallel unless overridden
+	DefaultBatchConcurrency = 16
+)
+
+// IPVerifierService is the domain service plus an entry point for policies
+// compiled up front
+type IPVerifierService interface {
+	domain.IPVerifierService
+	// VerifyCompiled is VerifyIP for a policy compiled by PolicyCompiler.Compile
+	VerifyCompiled(ctx context.Context, ip string, policy *CompiledPolicy) (*domain.VerifyResult, error)
+}
+
 type ipVerifierService struct {
-	repo domain.IPVerifierRepo
+	repo             domain.IPVerifierRepo
+	maxBatchSize     int
+	batchConcurrency int
+	compiler         *PolicyCompiler
+}
+
+// Option configures optional behaviour of the IP verifier service
+type Option func(*ipVerifierService)
+
+// WithMaxBatchSize sets the maximum number of IPs accepted by VerifyIPs
+func WithMaxBatchSize(n int) Option {
+	return func(s *ipVerifierService) {
+		s.maxBatchSize = n
+	}
+}
+
+// WithBatchConcurrency sets how many lookups VerifyIPs runs in parallel
+func WithBatchConcurrency(n int) Option {
+	return func(s *ipVerifierService) {
+		s.batchConcurrency = n
+	}
+}
+
+// WithPolicyCompiler sets the compiler holding the compiled named policies,
+// which must be the one given to the policy service
+func WithPolicyCompiler(c *PolicyCompiler) Option {
+	return func(s *ipVerifierService) {
+		s.compiler = c
+	}
 }
 
 // NewIPVerifierService creates a new instance of IPVerifierService
-func NewIPVerifierService(repo domain.IPVerifierRepo) domain.IPVerifierService {
-	return &ipVerifierService{
-		repo: repo,
+func NewIPVerifierService(repo domain.IPVerifierRepo, opts ...Option) IPVerifierService {
+	s := &ipVerifierService{
+		repo:             repo,
+		maxBatchSize:     DefaultMaxBatchSize,
+		batchConcurrency: DefaultBatchConcurrency,
+		compiler:         NewPolicyCompiler(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// VerifyIP checks if an IP address is from a country permitted by the policy
+func (s *ipVerifierService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (result *domain.VerifyResult, err error) {
+	ctx, span := tracer.Start(ctx, "ipVerifierService.VerifyIP")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Validate input
+	compiled, err := s.compiler.compile(policy)
+	if err != nil {
+		return nil, err
 	}
+	return s.verifyTraced(ctx, span, ip, compiled)
 }
 
-// VerifyIP checks if an IP address is from an allowed country
-func (s *ipVerifierService) VerifyIP(ctx context.Context, ip string, allowedCountries []string) (*domain.VerifyResult, error) {
+// VerifyCompiled checks an IP against a policy compiled by
+// PolicyCompiler.Compile, without compiling it again
+func (s *ipVerifierService) VerifyCompiled(ctx context.Context, ip string, policy *CompiledPolicy) (result *domain.VerifyResult, err error) {
+	ctx, span := tracer.Start(ctx, "ipVerifierService.VerifyCompiled")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.verifyTraced(ctx, span, ip, policy)
+}
+
+// verifyTraced verifies a single IP and records the decision on the span
+func (s *ipVerifierService) verifyTraced(ctx context.Context, span trace.Span, ip string, compiled *CompiledPolicy) (*domain.VerifyResult, error) {
+	result, err := s.verify(ctx, ip, compiled)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Bool("verify.allowed", result.Allowed),
+		attribute.String("verify.reason", string(result.Reason)),
+	)
+	slog.DebugContext(ctx, "IP verified",
+		"allowed", result.Allowed,
+		"reason", result.Reason,
+		"country", result.Country,
+	)
+	return result, nil
+}
+
+// VerifyIPs checks a batch of IP addresses against the same policy.
+// Lookup failures are reported per item and do not fail the whole batch.
+func (s *ipVerifierService) VerifyIPs(ctx context.Context, ips []string, policy domain.Policy) ([]domain.BatchResult, error) {
 	// Validate input
-	if len(allowedCountries) == 0 {
-		return nil, apperrors.NewValidationError("allowed_countries cannot be empty", nil)
+	if len(ips) == 0 {
+		return nil, apperrors.NewValidationError("ips cannot be empty", nil)
+	}
+	if len(ips) > s.maxBatchSize {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("batch cannot contain more than %d IPs", s.maxBatchSize), nil)
+	}
+	compiled, err := s.compiler.compile(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.BatchResult, len(ips))
+	indexes := make(chan int)
+
+	workers := min(max(s.batchConcurrency, 1), len(ips))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i] = domain.BatchResult{Err: apperrors.NewInternalError("Batch verification cancelled", err)}
+					continue
+				}
+				result, err := s.verify(ctx, ips[i], compiled)
+				results[i] = domain.BatchResult{Result: result, Err: err}
+			}
+		}()
+	}
+
+	for i := range ips {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, nil
+}
+
+// LookupIP returns the location, ASN and anonymity data of an IP without
+// applying a policy
+func (s *ipVerifierService) LookupIP(ctx context.Context, ip string) (*domain.LookupResult, error) {
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		if rangeType := classifyIP(addr); rangeType != "" {
+			return &domain.LookupResult{IP: ip, RangeType: rangeType}, nil
+		}
+	}
+
+	location, err := s.repo.GetCountryByIP(ctx, ip)
+	if err != nil && !apperrors.IsNotFoundError(err) {
+		return nil, err
+	}
+
+	asn, err := s.repo.GetASNByIP(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	anonymity, err := s.repo.GetAnonymityByIP(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LookupResult{
+		IP:        ip,
+		Location:  location,
+		ASN:       asn,
+		Anonymity: anonymity,
+	}, nil
+}
+
+// verify looks up a single IP and checks it against the compiled policy
+func (s *ipVerifierService) verify(ctx context.Context, ip string, compiled *CompiledPolicy) (*domain.VerifyResult, error) {
+	policy := compiled.policy
+
+	// CIDR overrides and special-purpose ranges are decided without a
+	// lookup. Unparseable input is left to the repo.
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		if override, ok := compiled.overrides.lookup(addr); ok {
+			return verifyOverride(ip, override, classifyIP(addr)), nil
+		}
+		if rangeType := classifyIP(addr); rangeType != "" {
+			return verifySpecialRange(ip, rangeType, policy)
+		}
+	}
+
+	// Get country for IP address; a missing country is decided below
+	location, lookupErr := s.repo.GetCountryByIP(ctx, ip)
+	if lookupErr != nil && !apperrors.IsNotFoundError(lookupErr) {
+		return nil, lookupErr
+	}
+
+	asn, err := s.repo.GetASNByIP(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	anonymity, err := s.repo.GetAnonymityByIP(ctx, ip)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get country for IP address
-	country, err := s.repo.GetCountryByIP(ctx, ip)
+	var result *domain.VerifyResult
+	if d, ok := evaluateASN(policy, asn); ok {
+		// ASN rules apply regardless of geography
+		result = &domain.VerifyResult{IP: ip, Allowed: d.allowed, Reason: d.reason, MatchedRule: d.rule}
+	} else if t, ok := deniedAnonymity(policy, anonymity); ok {
+		result = &domain.VerifyResult{
+			IP:          ip,
+			Allowed:     false,
+			Reason:      domain.ReasonAnonymousDenied,
+			MatchedRule: &domain.MatchedRule{List: "denied_anonymity", Entry: string(t)},
+		}
+	} else {
+		result, err = verifyLocation(ip, compiled, location, lookupErr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if location != nil {
+		setLocation(result, location)
+	}
+	if asn != nil {
+		result.ASN = asn.Number
+		result.ASOrg = asn.Organization
+	}
+	if anonymity != nil {
+		result.Anonymity = *anonymity
+	}
+	return result, nil
+}
+
+// verifyLocation applies the policy's country rules. notFound is the repo's
+// error when the database has no country for the IP.
+func verifyLocation(ip string, compiled *CompiledPolicy, location *domain.Location, notFound error) (*domain.VerifyResult, error) {
+	if notFound != nil {
+		return verifyUnknown(ip, compiled.policy, notFound)
+	}
+
+	d, ok := evaluateMatchMode(compiled, location)
+	if !ok {
+		// None of the countries the match mode checks is known
+		return verifyUnknown(ip, compiled.policy, apperrors.NewNotFoundError("No country found for IP address", nil))
+	}
+
+	return &domain.VerifyResult{
+		IP:           ip,
+		Allowed:      d.allowed,
+		Reason:       d.reason,
+		MatchedGroup: d.group,
+		MatchedRule:  d.rule,
+	}, nil
+}
+
+// setLocation copies the looked up countries into the result
+func setLocation(result *domain.VerifyResult, location *domain.Location) {
+	result.Country = location.Country
+	result.Continent = location.Continent
+	result.RegisteredCountry = location.RegisteredCountry
+	result.RepresentedCountry = location.RepresentedCountry
+	result.Subdivisions = location.Subdivisions
+	result.City = location.City
+	result.PostalCode = location.PostalCode
+	result.Coordinates = location.Coordinates
+	result.DatabaseBuild = location.DatabaseBuild
+}
+
+// verifyOverride reports the decision of a matching CIDR override
+func verifyOverride(ip string, override *cidrOverride, rangeType domain.RangeType) *domain.VerifyResult {
+	reason, list := domain.ReasonCIDRDenied, "denied_cidrs"
+	if override.allowed {
+		reason, list = domain.ReasonCIDRAllowed, "allowed_cidrs"
+	}
+	return &domain.VerifyResult{
+		IP:          ip,
+		Allowed:     override.allowed,
+		Reason:      reason,
+		RangeType:   rangeType,
+		MatchedRule: &domain.MatchedRule{List: list, Entry: override.prefix.String()},
+	}
+}
+
+// verifySpecialRange admits internal ranges when the policy allows them and
+// otherwise treats the IP as having an unknown location
+func verifySpecialRange(ip string, rangeType domain.RangeType, policy domain.Policy) (*domain.VerifyResult, error) {
+	if policy.AllowInternal && isInternalRange(rangeType) {
+		return &domain.VerifyResult{
+			IP:          ip,
+			Allowed:     true,
+			Reason:      domain.ReasonPrivateRange,
+			RangeType:   rangeType,
+			MatchedRule: &domain.MatchedRule{List: "allow_internal", Entry: string(rangeType)},
+		}, nil
+	}
+
+	notFound := apperrors.NewNotFoundError("No country found for IP address", nil)
+	result, err := verifyUnknown(ip, policy, notFound)
 	if err != nil {
 		return nil, err
 	}
+	result.RangeType = rangeType
+	return result, nil
+}
 
-	// Check if country is in allowed list
-	allowed := contains(allowedCountries, country)
+// verifyUnknown applies the policy's unknown action to an IP without a country
+func verifyUnknown(ip string, policy domain.Policy, notFound error) (*domain.VerifyResult, error) {
+	action := policy.UnknownAction
+	if action == "" {
+		action = domain.UnknownActionDeny
+	}
+	if action == domain.UnknownActionError {
+		return nil, notFound
+	}
 
 	return &domain.VerifyResult{
-		IP:      ip,
-		Country: country,
-		Allowed: allowed,
+		IP:          ip,
+		Allowed:     action == domain.UnknownActionAllow,
+		Reason:      domain.ReasonUnknownLocation,
+		MatchedRule: &domain.MatchedRule{List: "unknown_action", Entry: string(action)},
 	}, nil
 }
 
-// contains checks if a string slice contains a specific value
-func contains(slice []string, value string) bool {
-	for _, item := range slice {
-		if item == value {
-			return true
+// ValidatePolicy checks an inline policy the way VerifyIP does, so callers
+// holding a fixed policy can reject it before the first verification
+func ValidatePolicy(policy domain.Policy) error {
+	_, err := compilePolicy(policy)
+	return err
+}
+
+// validatePolicy ensures the policy has at least one rule and a known unknown
+// action. Country and CIDR lists are checked when they are compiled.
+func validatePolicy(policy domain.Policy) error {
+	if !policy.HasRules() {
+		return apperrors.NewValidationError(
+			"allowed_countries, denied_countries, allowed_asns, denied_asns, allowed_cidrs, denied_cidrs or denied_anonymity must be provided", nil)
+	}
+	if slices.Contains(policy.AllowedASNs, 0) || slices.Contains(policy.DeniedASNs, 0) {
+		return apperrors.NewValidationError("ASN lists cannot contain 0", nil)
+	}
+	for _, t := range policy.DeniedAnonymity {
+		switch t {
+		case domain.AnonymityVPN, domain.AnonymityTor, domain.AnonymityHosting, domain.AnonymityPublicProxy:
+		default:
+			return apperrors.NewValidationError(
+				fmt.Sprintf("denied_anonymity contains unknown type %q; must be vpn, tor, hosting or public_proxy", t), nil)
+		}
+	}
+
+	switch policy.UnknownAction {
+	case "", domain.UnknownActionAllow, domain.UnknownActionDeny, domain.UnknownActionError:
+	default:
+		return apperrors.NewValidationError("unknown_action must be one of allow, deny or error", nil)
+	}
+
+	switch policy.MatchMode {
+	case "", domain.MatchPhysical, domain.MatchRegistered, domain.MatchAny, domain.MatchAll:
+	default:
+		return apperrors.NewValidationError("match_mode must be one of physical, registered, any or all", nil)
+	}
+	return nil
+}
+
+// decision is the outcome of applying a policy to one country or ASN
+type decision struct {
+	allowed bool
+	reason  domain.Reason
+	group   string
+	rule    *domain.MatchedRule
+}
+
+// evaluateASN applies the policy's ASN lists. It returns false when neither
+// list matches, leaving the decision to the country rules.
+func evaluateASN(policy domain.Policy, asn *domain.ASN) (decision, bool) {
+	if asn == nil {
+		return decision{}, false
+	}
+	entry := strconv.FormatUint(uint64(asn.Number), 10)
+	if slices.Contains(policy.DeniedASNs, asn.Number) {
+		return decision{
+			allowed: false,
+			reason:  domain.ReasonASNDenied,
+			rule:    &domain.MatchedRule{List: "denied_asns", Entry: entry},
+		}, true
+	}
+	if slices.Contains(policy.AllowedASNs, asn.Number) {
+		return decision{
+			allowed: true,
+			reason:  domain.ReasonASNAllowed,
+			rule:    &domain.MatchedRule{List: "allowed_asns", Entry: entry},
+		}, true
+	}
+	return decision{}, false
+}
+
+// deniedAnonymity returns the first anonymity type the policy denies that the
+// IP belongs to
+func deniedAnonymity(policy domain.Policy, anonymity *domain.Anonymity) (domain.AnonymityType, bool) {
+	if anonymity == nil {
+		return "", false
+	}
+	for _, t := range policy.DeniedAnonymity {
+		switch {
+		case t == domain.AnonymityVPN && anonymity.IsVPN,
+			t == domain.AnonymityTor && anonymity.IsTor,
+			t == domain.AnonymityHosting && anonymity.IsHosting,
+			t == domain.AnonymityPublicProxy && anonymity.IsPublicProxy:
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// evaluateMatchMode applies the policy to the countries selected by its match
+// mode. It returns false when none of those countries is known.
+func evaluateMatchMode(compiled *CompiledPolicy, location *domain.Location) (decision, bool) {
+	candidates := matchCandidates(compiled.policy.MatchMode, location)
+	if len(candidates) == 0 {
+		return decision{}, false
+	}
+
+	decisions := make([]decision, len(candidates))
+	for i, candidate := range candidates {
+		decisions[i] = evaluateLocation(compiled, candidate)
+	}
+
+	switch compiled.policy.MatchMode {
+	case domain.MatchAny:
+		// The first allowed country wins; otherwise report the first denial
+		for _, d := range decisions {
+			if d.allowed {
+				return d, true
+			}
+		}
+		return decisions[0], true
+	default:
+		// Every country must pass; report the first denial
+		for _, d := range decisions {
+			if !d.allowed {
+				return d, true
+			}
+		}
+		return decisions[0], true
+	}
+}
+
+// matchCandidates returns the known countries a match mode checks, each as a
+// location of its own. Only the physical country carries a continent and
+// subdivisions.
+func matchCandidates(mode domain.MatchMode, location *domain.Location) []*domain.Location {
+	physical := &domain.Location{Country: location.Country, Continent: location.Continent, Subdivisions: location.Subdivisions}
+	registered := &domain.Location{Country: location.RegisteredCountry}
+	represented := &domain.Location{Country: location.RepresentedCountry}
+
+	var all []*domain.Location
+	switch mode {
+	case domain.MatchRegistered:
+		all = []*domain.Location{registered}
+	case domain.MatchAny, domain.MatchAll:
+		all = []*domain.Location{physical, registered, represented}
+	default:
+		all = []*domain.Location{physical}
+	}
+
+	candidates := make([]*domain.Location, 0, len(all))
+	for _, candidate := range all {
+		if candidate.Country != "" {
+			candidates = append(candidates, candidate)
 		}
 	}
-	return false
+	return candidates
+}
+
+// evaluateLocation applies the policy to a single country. The deny list
+// takes precedence; an empty allow list admits every country that is not
+// denied. The entry that decides the result is recorded.
+func evaluateLocation(compiled *CompiledPolicy, location *domain.Location) decision {
+	policy := compiled.policy
+	if r, ok := matchRules(compiled.denied, location); ok {
+		return decision{
+			allowed: false,
+			reason:  domain.ReasonCountryDenied,
+			group:   groupName(r),
+			rule:    &domain.MatchedRule{List: "denied_countries", Entry: r.String()},
+		}
+	}
+	if len(policy.AllowedCountries) == 0 {
+		// An ASN or CIDR allow list on its own admits no country
+		if len(policy.AllowedASNs) > 0 || len(policy.AllowedCIDRs) > 0 {
+			return decision{allowed: false, reason: domain.ReasonNotInAllowlist}
+		}
+		return decision{allowed: true, reason: domain.ReasonCountryAllowed}
+	}
+	if r, ok := matchRules(compiled.allowed, location); ok {
+		return decision{
+			allowed: true,
+			reason:  domain.ReasonCountryAllowed,
+			group:   groupName(r),
+			rule:    &domain.MatchedRule{List: "allowed_countries", Entry: r.String()},
+		}
+	}
+	return decision{allowed: false, reason: domain.ReasonNotInAllowlist}
+}
+
+// groupName returns the entry of a continent or group rule, or "" for a
+// country or subdivision rule
+func groupName(r rule) string {
+	if r.kind != ruleContinent && r.kind != ruleGroup {
+		return ""
+	}
+	return r.String()
 }
 
 // HealthCheck verifies the repository is healthy
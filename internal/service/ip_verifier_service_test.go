@@ -3,23 +3,45 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/tracing/tracingtest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MockIPVerifierRepo is a mock implementation of domain.IPVerifierRepo
 type MockIPVerifierRepo struct {
-	GetCountryByIPFunc func(ctx context.Context, ipAddress string) (string, error)
-	HealthCheckFunc    func(ctx context.Context) error
+	GetCountryByIPFunc   func(ctx context.Context, ipAddress string) (*domain.Location, error)
+	GetASNByIPFunc       func(ctx context.Context, ipAddress string) (*domain.ASN, error)
+	GetAnonymityByIPFunc func(ctx context.Context, ipAddress string) (*domain.Anonymity, error)
+	HealthCheckFunc      func(ctx context.Context) error
 }
 
-func (m *MockIPVerifierRepo) GetCountryByIP(ctx context.Context, ipAddress string) (string, error) {
+func (m *MockIPVerifierRepo) GetCountryByIP(ctx context.Context, ipAddress string) (*domain.Location, error) {
 	if m.GetCountryByIPFunc != nil {
 		return m.GetCountryByIPFunc(ctx, ipAddress)
 	}
-	return "US", nil
+	return &domain.Location{Country: "US"}, nil
+}
+
+func (m *MockIPVerifierRepo) GetASNByIP(ctx context.Context, ipAddress string) (*domain.ASN, error) {
+	if m.GetASNByIPFunc != nil {
+		return m.GetASNByIPFunc(ctx, ipAddress)
+	}
+	return nil, nil
+}
+
+func (m *MockIPVerifierRepo) GetAnonymityByIP(ctx context.Context, ipAddress string) (*domain.Anonymity, error) {
+	if m.GetAnonymityByIPFunc != nil {
+		return m.GetAnonymityByIPFunc(ctx, ipAddress)
+	}
+	return nil, nil
 }
 
 func (m *MockIPVerifierRepo) HealthCheck(ctx context.Context) error {
@@ -31,15 +53,15 @@ func (m *MockIPVerifierRepo) HealthCheck(ctx context.Context) error {
 
 func TestVerifyIP_Success_Allowed(t *testing.T) {
 	mockRepo := &MockIPVerifierRepo{
-		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (string, error) {
-			return "US", nil
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return &domain.Location{Country: "US"}, nil
 		},
 	}
 
 	service := NewIPVerifierService(mockRepo)
 	ctx := context.Background()
 
-	result, err := service.VerifyIP(ctx, "8.8.8.8", []string{"US", "CA"})
+	result, err := service.VerifyIP(ctx, "8.8.8.8", domain.Policy{AllowedCountries: []string{"US", "CA"}})
 
 	require.NoError(t, err)
 	assert.Equal(t, "8.8.8.8", result.IP)
@@ -49,15 +71,15 @@ func TestVerifyIP_Success_Allowed(t *testing.T) {
 
 func TestVerifyIP_Success_NotAllowed(t *testing.T) {
 	mockRepo := &MockIPVerifierRepo{
-		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (string, error) {
-			return "CN", nil
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return &domain.Location{Country: "CN"}, nil
 		},
 	}
 
 	service := NewIPVerifierService(mockRepo)
 	ctx := context.Background()
 
-	result, err := service.VerifyIP(ctx, "1.2.3.4", []string{"US", "CA"})
+	result, err := service.VerifyIP(ctx, "1.2.3.4", domain.Policy{AllowedCountries: []string{"US", "CA"}})
 
 	require.NoError(t, err)
 	assert.Equal(t, "1.2.3.4", result.IP)
@@ -65,31 +87,747 @@ func TestVerifyIP_Success_NotAllowed(t *testing.T) {
 	assert.False(t, result.Allowed)
 }
 
+func TestVerifyIP_Tracing(t *testing.T) {
+	var repoParent trace.SpanContext
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			repoParent = trace.SpanContextFromContext(ctx)
+			return &domain.Location{Country: "CN"}, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx, parent := tracingtest.NewParent(t.Context())
+
+	_, err := service.VerifyIP(ctx, "1.2.3.4", domain.Policy{AllowedCountries: []string{"US", "CA"}})
+	require.NoError(t, err)
+
+	spans := tracingtest.Spans(parent.TraceID())
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "ipVerifierService.VerifyIP", span.Name())
+	assert.Equal(t, parent.SpanID(), span.Parent().SpanID())
+	assert.Equal(t, span.SpanContext().SpanID(), repoParent.SpanID(), "repo should be called with the service span")
+	assert.Contains(t, span.Attributes(), attribute.Bool("verify.allowed", false))
+	assert.Contains(t, span.Attributes(), attribute.String("verify.reason", string(domain.ReasonNotInAllowlist)))
+}
+
 func TestVerifyIP_EmptyAllowedCountries(t *testing.T) {
 	mockRepo := &MockIPVerifierRepo{}
 	service := NewIPVerifierService(mockRepo)
 	ctx := context.Background()
 
-	result, err := service.VerifyIP(ctx, "8.8.8.8", []string{})
+	result, err := service.VerifyIP(ctx, "8.8.8.8", domain.Policy{})
 
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "allowed_countries")
 }
 
+func TestVerifyIP_DeniedCountries(t *testing.T) {
+	countries := map[string]string{
+		"8.8.8.8":   "US",
+		"77.88.8.8": "RU",
+		"1.1.1.1":   "AU",
+	}
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			if country, ok := countries[ipAddress]; ok {
+				return &domain.Location{Country: country}, nil
+			}
+			return nil, apperrors.NewNotFoundError("No country found for IP address", nil)
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ip       string
+		policy   domain.Policy
+		expected bool
+	}{
+		{"deny list only, not denied", "8.8.8.8", domain.Policy{DeniedCountries: []string{"RU"}}, true},
+		{"deny list only, denied", "77.88.8.8", domain.Policy{DeniedCountries: []string{"RU"}}, false},
+		{"deny wins over allow", "77.88.8.8", domain.Policy{AllowedCountries: []string{"RU", "US"}, DeniedCountries: []string{"RU"}}, false},
+		{"both lists, allowed", "8.8.8.8", domain.Policy{AllowedCountries: []string{"US"}, DeniedCountries: []string{"RU"}}, true},
+		{"both lists, not in allow list", "1.1.1.1", domain.Policy{AllowedCountries: []string{"US"}, DeniedCountries: []string{"RU"}}, false},
+		{"deny list only, no country", "10.0.0.1", domain.Policy{DeniedCountries: []string{"RU"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+		})
+	}
+}
+
+func TestVerifyIP_Reasons(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return &domain.Location{Country: "RU"}, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		policy   domain.Policy
+		expected domain.Reason
+	}{
+		{"in allow list", domain.Policy{AllowedCountries: []string{"RU"}}, domain.ReasonCountryAllowed},
+		{"not denied", domain.Policy{DeniedCountries: []string{"KP"}}, domain.ReasonCountryAllowed},
+		{"denied", domain.Policy{DeniedCountries: []string{"RU"}}, domain.ReasonCountryDenied},
+		{"not in allow list", domain.Policy{AllowedCountries: []string{"US"}}, domain.ReasonNotInAllowlist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, "77.88.8.8", tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Reason)
+		})
+	}
+}
+
+func TestVerifyIP_ContinentsAndGroups(t *testing.T) {
+	locations := map[string]*domain.Location{
+		"2.2.2.2":   {Country: "DE", Continent: "EU"},
+		"77.88.8.8": {Country: "RU", Continent: "EU"},
+		"41.0.0.1":  {Country: "ZA", Continent: "AF"},
+		"8.8.8.8":   {Country: "US", Continent: "NA"},
+		"5.5.5.5":   {Country: "NO", Continent: "EU"},
+	}
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return locations[ipAddress], nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		ip            string
+		policy        domain.Policy
+		expected      bool
+		reason        domain.Reason
+		expectedGroup string
+	}{
+		{"allow EU group", "2.2.2.2", domain.Policy{AllowedCountries: []string{"group:EU"}}, true, domain.ReasonCountryAllowed, "group:EU"},
+		{"EU group excludes Norway", "5.5.5.5", domain.Policy{AllowedCountries: []string{"group:EU"}}, false, domain.ReasonNotInAllowlist, ""},
+		{"EEA group includes Norway", "5.5.5.5", domain.Policy{AllowedCountries: []string{"group:eea"}}, true, domain.ReasonCountryAllowed, "group:EEA"},
+		{"deny continent", "41.0.0.1", domain.Policy{DeniedCountries: []string{"continent:AF"}}, false, domain.ReasonCountryDenied, "continent:AF"},
+		{"allow continent minus country", "77.88.8.8", domain.Policy{AllowedCountries: []string{"continent:EU"}, DeniedCountries: []string{"RU"}}, false, domain.ReasonCountryDenied, ""},
+		{"country entry has no group", "8.8.8.8", domain.Policy{AllowedCountries: []string{"US", "group:EU"}}, true, domain.ReasonCountryAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.expectedGroup, result.MatchedGroup)
+		})
+	}
+}
+
+func TestVerifyIP_MatchModes(t *testing.T) {
+	locations := map[string]*domain.Location{
+		// Physically in Germany, registered to and represented by the US
+		"3.3.3.3": {Country: "DE", Continent: "EU", RegisteredCountry: "US", RepresentedCountry: "US"},
+		// Registered country only
+		"9.9.9.9": {RegisteredCountry: "FR"},
+	}
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return locations[ipAddress], nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ip       string
+		policy   domain.Policy
+		expected bool
+		reason   domain.Reason
+	}{
+		{"physical default", "3.3.3.3", domain.Policy{AllowedCountries: []string{"DE"}}, true, domain.ReasonCountryAllowed},
+		{"physical ignores registered", "3.3.3.3", domain.Policy{DeniedCountries: []string{"US"}, MatchMode: domain.MatchPhysical}, true, domain.ReasonCountryAllowed},
+		{"registered", "3.3.3.3", domain.Policy{AllowedCountries: []string{"DE"}, MatchMode: domain.MatchRegistered}, false, domain.ReasonNotInAllowlist},
+		{"registered continents do not apply", "3.3.3.3", domain.Policy{AllowedCountries: []string{"continent:EU"}, MatchMode: domain.MatchRegistered}, false, domain.ReasonNotInAllowlist},
+		{"any allows on registered", "3.3.3.3", domain.Policy{AllowedCountries: []string{"US"}, MatchMode: domain.MatchAny}, true, domain.ReasonCountryAllowed},
+		{"any denies when none pass", "3.3.3.3", domain.Policy{DeniedCountries: []string{"DE", "US"}, MatchMode: domain.MatchAny}, false, domain.ReasonCountryDenied},
+		{"all denies on represented", "3.3.3.3", domain.Policy{DeniedCountries: []string{"US"}, MatchMode: domain.MatchAll}, false, domain.ReasonCountryDenied},
+		{"all allows when every country passes", "3.3.3.3", domain.Policy{AllowedCountries: []string{"DE", "US"}, MatchMode: domain.MatchAll}, true, domain.ReasonCountryAllowed},
+		{"physical unknown", "9.9.9.9", domain.Policy{AllowedCountries: []string{"FR"}}, false, domain.ReasonUnknownLocation},
+		{"registered known", "9.9.9.9", domain.Policy{AllowedCountries: []string{"FR"}, MatchMode: domain.MatchRegistered}, true, domain.ReasonCountryAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, locations[tt.ip].RegisteredCountry, result.RegisteredCountry)
+			assert.Equal(t, locations[tt.ip].RepresentedCountry, result.RepresentedCountry)
+		})
+	}
+}
+
+func TestVerifyIP_InvalidMatchMode(t *testing.T) {
+	service := NewIPVerifierService(&MockIPVerifierRepo{})
+
+	result, err := service.VerifyIP(context.Background(), "8.8.8.8",
+		domain.Policy{AllowedCountries: []string{"US"}, MatchMode: "nearest"})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
+func TestVerifyIP_Subdivisions(t *testing.T) {
+	location := &domain.Location{
+		Country:      "US",
+		Continent:    "NA",
+		Subdivisions: []string{"CA"},
+		City:         "Mountain View",
+		PostalCode:   "94043",
+		Coordinates:  &domain.Coordinates{Latitude: 37.4, Longitude: -122.1},
+	}
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return location, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		policy   domain.Policy
+		expected bool
+		reason   domain.Reason
+	}{
+		{"allowed state", domain.Policy{AllowedCountries: []string{"US-CA", "CA-QC"}}, true, domain.ReasonCountryAllowed},
+		{"other state", domain.Policy{AllowedCountries: []string{"US-NY"}}, false, domain.ReasonNotInAllowlist},
+		{"country minus state", domain.Policy{AllowedCountries: []string{"US"}, DeniedCountries: []string{"US-CA"}}, false, domain.ReasonCountryDenied},
+		{"registered mode has no subdivisions", domain.Policy{AllowedCountries: []string{"US-CA"}, MatchMode: domain.MatchRegistered}, false, domain.ReasonUnknownLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, "8.8.8.8", tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, []string{"CA"}, result.Subdivisions)
+			assert.Equal(t, "Mountain View", result.City)
+			assert.Equal(t, "94043", result.PostalCode)
+			assert.Equal(t, location.Coordinates, result.Coordinates)
+		})
+	}
+}
+
+func TestVerifyIP_ASNRules(t *testing.T) {
+	locations := map[string]*domain.Location{
+		"8.8.8.8": {Country: "US", Continent: "NA"},
+		"5.5.5.5": {Country: "NL", Continent: "EU"},
+	}
+	asns := map[string]*domain.ASN{
+		"8.8.8.8":     {Number: 15169, Organization: "GOOGLE"},
+		"5.5.5.5":     {Number: 64500, Organization: "HOSTING"},
+		"45.67.89.10": {Number: 64501, Organization: "PARTNER"},
+	}
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			if location, ok := locations[ipAddress]; ok {
+				return location, nil
+			}
+			return nil, apperrors.NewNotFoundError("No country found for IP address", nil)
+		},
+		GetASNByIPFunc: func(ctx context.Context, ipAddress string) (*domain.ASN, error) {
+			return asns[ipAddress], nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ip       string
+		policy   domain.Policy
+		expected bool
+		reason   domain.Reason
+	}{
+		{"denied ASN in allowed country", "5.5.5.5", domain.Policy{AllowedCountries: []string{"NL"}, DeniedASNs: []uint{64500}}, false, domain.ReasonASNDenied},
+		{"allowed ASN in other country", "8.8.8.8", domain.Policy{AllowedCountries: []string{"NL"}, AllowedASNs: []uint{15169}}, true, domain.ReasonASNAllowed},
+		{"allowed ASN in denied country", "8.8.8.8", domain.Policy{DeniedCountries: []string{"US"}, AllowedASNs: []uint{15169}}, true, domain.ReasonASNAllowed},
+		{"deny list wins over allow list", "5.5.5.5", domain.Policy{AllowedASNs: []uint{64500}, DeniedASNs: []uint{64500}}, false, domain.ReasonASNDenied},
+		{"ASN allow list only", "5.5.5.5", domain.Policy{AllowedASNs: []uint{15169}}, false, domain.ReasonNotInAllowlist},
+		{"ASN deny list only", "8.8.8.8", domain.Policy{DeniedASNs: []uint{64500}}, true, domain.ReasonCountryAllowed},
+		{"allowed ASN without country", "45.67.89.10", domain.Policy{AllowedASNs: []uint{64501}, UnknownAction: domain.UnknownActionError}, true, domain.ReasonASNAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, asns[tt.ip].Number, result.ASN)
+			assert.Equal(t, asns[tt.ip].Organization, result.ASOrg)
+		})
+	}
+}
+
+func TestVerifyIP_InvalidASN(t *testing.T) {
+	service := NewIPVerifierService(&MockIPVerifierRepo{})
+
+	result, err := service.VerifyIP(context.Background(), "8.8.8.8", domain.Policy{DeniedASNs: []uint{0}})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
+func TestVerifyIP_CIDROverrides(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			if ipAddress != "8.8.8.8" {
+				t.Fatalf("override for %s should skip the lookup", ipAddress)
+			}
+			return &domain.Location{Country: "US"}, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	policy := domain.Policy{
+		AllowedCountries: []string{"DE"},
+		DeniedCountries:  []string{"RU"},
+		AllowedCIDRs:     []string{"77.88.0.0/16", "10.0.0.0/8"},
+		DeniedCIDRs:      []string{"77.88.8.0/24", "2.2.0.0/16"},
+	}
+
+	tests := []struct {
+		name      string
+		ip        string
+		expected  bool
+		reason    domain.Reason
+		rangeType domain.RangeType
+	}{
+		{"allowed override in denied country", "77.88.1.1", true, domain.ReasonCIDRAllowed, ""},
+		{"more specific deny wins", "77.88.8.8", false, domain.ReasonCIDRDenied, ""},
+		{"denied override in allowed country", "2.2.2.2", false, domain.ReasonCIDRDenied, ""},
+		{"allowed override in private range", "10.0.0.5", true, domain.ReasonCIDRAllowed, domain.RangePrivate},
+		{"no override", "8.8.8.8", false, domain.ReasonNotInAllowlist, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.rangeType, result.RangeType)
+		})
+	}
+}
+
+func TestVerifyIP_CIDRAllowListOnly(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return &domain.Location{Country: "US"}, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		policy   domain.Policy
+		ip       string
+		expected bool
+		reason   domain.Reason
+	}{
+		{"listed range", domain.Policy{AllowedCIDRs: []string{"198.51.100.0/24"}}, "198.51.100.7", true, domain.ReasonCIDRAllowed},
+		{"other range", domain.Policy{AllowedCIDRs: []string{"198.51.100.0/24"}}, "8.8.8.8", false, domain.ReasonNotInAllowlist},
+		{"other range with deny list", domain.Policy{AllowedCIDRs: []string{"198.51.100.0/24"}, DeniedCountries: []string{"RU"}}, "8.8.8.8", false, domain.ReasonNotInAllowlist},
+		{"denied ranges only", domain.Policy{DeniedCIDRs: []string{"198.51.100.0/24"}}, "8.8.8.8", true, domain.ReasonCountryAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+		})
+	}
+}
+
+func TestVerifyIP_InvalidCIDR(t *testing.T) {
+	service := NewIPVerifierService(&MockIPVerifierRepo{})
+
+	result, err := service.VerifyIP(context.Background(), "8.8.8.8",
+		domain.Policy{AllowedCIDRs: []string{"10.0.0.0/40"}})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
+func TestVerifyIP_DeniedAnonymity(t *testing.T) {
+	anonymity := map[string]*domain.Anonymity{
+		"5.5.5.5": {IsAnonymous: true, IsVPN: true, IsHosting: true},
+		"6.6.6.6": {IsAnonymous: true, IsTor: true},
+		"7.7.7.7": {IsAnonymous: true, IsPublicProxy: true},
+	}
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return &domain.Location{Country: "NL"}, nil
+		},
+		GetASNByIPFunc: func(ctx context.Context, ipAddress string) (*domain.ASN, error) {
+			return &domain.ASN{Number: 64500}, nil
+		},
+		GetAnonymityByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Anonymity, error) {
+			return anonymity[ipAddress], nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ip       string
+		policy   domain.Policy
+		expected bool
+		reason   domain.Reason
+	}{
+		{"VPN denied in allowed country", "5.5.5.5", domain.Policy{AllowedCountries: []string{"NL"}, DeniedAnonymity: []domain.AnonymityType{domain.AnonymityVPN}}, false, domain.ReasonAnonymousDenied},
+		{"hosting denied", "5.5.5.5", domain.Policy{DeniedAnonymity: []domain.AnonymityType{domain.AnonymityHosting}}, false, domain.ReasonAnonymousDenied},
+		{"Tor denied", "6.6.6.6", domain.Policy{DeniedAnonymity: []domain.AnonymityType{domain.AnonymityTor}}, false, domain.ReasonAnonymousDenied},
+		{"public proxy denied", "7.7.7.7", domain.Policy{DeniedAnonymity: []domain.AnonymityType{domain.AnonymityPublicProxy}}, false, domain.ReasonAnonymousDenied},
+		{"other type not denied", "6.6.6.6", domain.Policy{DeniedAnonymity: []domain.AnonymityType{domain.AnonymityVPN}}, true, domain.ReasonCountryAllowed},
+		{"plain IP not denied", "8.8.8.8", domain.Policy{AllowedCountries: []string{"NL"}, DeniedAnonymity: []domain.AnonymityType{domain.AnonymityVPN}}, true, domain.ReasonCountryAllowed},
+		{"allowed ASN wins", "5.5.5.5", domain.Policy{AllowedASNs: []uint{64500}, DeniedAnonymity: []domain.AnonymityType{domain.AnonymityVPN}}, true, domain.ReasonASNAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.reason, result.Reason)
+			if flags := anonymity[tt.ip]; flags != nil {
+				assert.Equal(t, *flags, result.Anonymity)
+			}
+		})
+	}
+}
+
+func TestVerifyIP_InvalidAnonymityType(t *testing.T) {
+	service := NewIPVerifierService(&MockIPVerifierRepo{})
+
+	result, err := service.VerifyIP(context.Background(), "8.8.8.8",
+		domain.Policy{DeniedAnonymity: []domain.AnonymityType{"relay"}})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
+func TestVerifyIP_MatchedRule(t *testing.T) {
+	build := time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC)
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			if ipAddress == "45.67.89.10" {
+				return nil, apperrors.NewNotFoundError("No country found for IP address", nil)
+			}
+			return &domain.Location{Country: "DE", Continent: "EU", Subdivisions: []string{"BY"}, DatabaseBuild: build}, nil
+		},
+		GetASNByIPFunc: func(ctx context.Context, ipAddress string) (*domain.ASN, error) {
+			return &domain.ASN{Number: 3320}, nil
+		},
+		GetAnonymityByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Anonymity, error) {
+			return &domain.Anonymity{IsAnonymous: true, IsHosting: true}, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ip       string
+		policy   domain.Policy
+		expected *domain.MatchedRule
+		build    time.Time
+	}{
+		{"country allowed", "2.2.2.2", domain.Policy{AllowedCountries: []string{"US", "DE"}}, &domain.MatchedRule{List: "allowed_countries", Entry: "DE"}, build},
+		{"group denied", "2.2.2.2", domain.Policy{DeniedCountries: []string{"group:EU"}}, &domain.MatchedRule{List: "denied_countries", Entry: "group:EU"}, build},
+		{"subdivision allowed", "2.2.2.2", domain.Policy{AllowedCountries: []string{"DE-BY"}}, &domain.MatchedRule{List: "allowed_countries", Entry: "DE-BY"}, build},
+		{"not in allow list", "2.2.2.2", domain.Policy{AllowedCountries: []string{"US"}}, nil, build},
+		{"no denied entry matches", "2.2.2.2", domain.Policy{DeniedCountries: []string{"US"}}, nil, build},
+		{"ASN denied", "2.2.2.2", domain.Policy{DeniedASNs: []uint{3320}}, &domain.MatchedRule{List: "denied_asns", Entry: "3320"}, build},
+		{"anonymity denied", "2.2.2.2", domain.Policy{DeniedAnonymity: []domain.AnonymityType{domain.AnonymityVPN, domain.AnonymityHosting}}, &domain.MatchedRule{List: "denied_anonymity", Entry: "hosting"}, build},
+		{"CIDR denied", "2.2.2.2", domain.Policy{DeniedCIDRs: []string{"2.2.0.0/16"}}, &domain.MatchedRule{List: "denied_cidrs", Entry: "2.2.0.0/16"}, time.Time{}},
+		{"internal range allowed", "10.0.0.5", domain.Policy{AllowedCountries: []string{"DE"}, AllowInternal: true}, &domain.MatchedRule{List: "allow_internal", Entry: "private"}, time.Time{}},
+		{"unknown location", "45.67.89.10", domain.Policy{AllowedCountries: []string{"DE"}}, &domain.MatchedRule{List: "unknown_action", Entry: "deny"}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.MatchedRule)
+			assert.Equal(t, tt.build, result.DatabaseBuild)
+		})
+	}
+}
+
+func TestVerifyIP_UnknownGroup(t *testing.T) {
+	service := NewIPVerifierService(&MockIPVerifierRepo{})
+	ctx := context.Background()
+
+	for _, entry := range []string{"group:NATO", "continent:XX"} {
+		t.Run(entry, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, "8.8.8.8", domain.Policy{AllowedCountries: []string{entry}})
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.True(t, apperrors.IsValidationError(err))
+		})
+	}
+}
+
+func TestVerifyIP_UnknownLocation(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return nil, apperrors.NewNotFoundError("No country found for IP address", nil)
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		action   domain.UnknownAction
+		expected bool
+	}{
+		{"default denies", "", false},
+		{"deny", domain.UnknownActionDeny, false},
+		{"allow", domain.UnknownActionAllow, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := domain.Policy{AllowedCountries: []string{"US"}, UnknownAction: tt.action}
+			result, err := service.VerifyIP(ctx, "45.67.89.10", policy)
+			require.NoError(t, err)
+			assert.Equal(t, "45.67.89.10", result.IP)
+			assert.Empty(t, result.Country)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, domain.ReasonUnknownLocation, result.Reason)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		policy := domain.Policy{AllowedCountries: []string{"US"}, UnknownAction: domain.UnknownActionError}
+		result, err := service.VerifyIP(ctx, "45.67.89.10", policy)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.True(t, apperrors.IsNotFoundError(err))
+	})
+}
+
+func TestVerifyIP_SpecialRanges(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			t.Fatalf("special-purpose address %s should not be looked up", ipAddress)
+			return nil, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		ip            string
+		policy        domain.Policy
+		expected      bool
+		expectedRange domain.RangeType
+		reason        domain.Reason
+	}{
+		{"private allowed as internal", "10.0.0.5", domain.Policy{AllowedCountries: []string{"US"}, AllowInternal: true}, true, domain.RangePrivate, domain.ReasonPrivateRange},
+		{"cgnat allowed as internal", "100.64.1.1", domain.Policy{AllowedCountries: []string{"US"}, AllowInternal: true}, true, domain.RangeCGNAT, domain.ReasonPrivateRange},
+		{"loopback v6 allowed as internal", "::1", domain.Policy{AllowedCountries: []string{"US"}, AllowInternal: true}, true, domain.RangeLoopback, domain.ReasonPrivateRange},
+		{"private without allow_internal", "10.0.0.5", domain.Policy{AllowedCountries: []string{"US"}}, false, domain.RangePrivate, domain.ReasonUnknownLocation},
+		{"documentation is never internal", "192.0.2.1", domain.Policy{AllowedCountries: []string{"US"}, AllowInternal: true}, false, domain.RangeDocumentation, domain.ReasonUnknownLocation},
+		{"unknown action still applies", "192.168.0.1", domain.Policy{AllowedCountries: []string{"US"}, UnknownAction: domain.UnknownActionAllow}, true, domain.RangePrivate, domain.ReasonUnknownLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.VerifyIP(ctx, tt.ip, tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Allowed)
+			assert.Equal(t, tt.expectedRange, result.RangeType)
+			assert.Equal(t, tt.reason, result.Reason)
+		})
+	}
+}
+
+func TestVerifyIP_InvalidUnknownAction(t *testing.T) {
+	service := NewIPVerifierService(&MockIPVerifierRepo{})
+	ctx := context.Background()
+
+	policy := domain.Policy{AllowedCountries: []string{"US"}, UnknownAction: "ignore"}
+	result, err := service.VerifyIP(ctx, "8.8.8.8", policy)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
 func TestVerifyIP_RepoError(t *testing.T) {
 	mockRepo := &MockIPVerifierRepo{
-		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (string, error) {
-			return "", errors.New("invalid IP address")
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			return nil, errors.New("invalid IP address")
 		},
 	}
 
 	service := NewIPVerifierService(mockRepo)
 	ctx := context.Background()
 
-	result, err := service.VerifyIP(ctx, "invalid-ip", []string{"US"})
+	result, err := service.VerifyIP(ctx, "invalid-ip", domain.Policy{AllowedCountries: []string{"US"}})
 
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "invalid IP address")
 }
+
+func TestVerifyIPs_PerItemErrors(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			switch ipAddress {
+			case "8.8.8.8":
+				return &domain.Location{Country: "US"}, nil
+			case "77.88.8.8":
+				return &domain.Location{Country: "RU"}, nil
+			default:
+				return nil, apperrors.NewValidationError("Invalid IP address", nil)
+			}
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo, WithBatchConcurrency(2))
+	ctx := context.Background()
+
+	results, err := service.VerifyIPs(ctx, []string{"8.8.8.8", "not-an-ip", "77.88.8.8"}, domain.Policy{AllowedCountries: []string{"US"}})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "8.8.8.8", results[0].Result.IP)
+	assert.True(t, results[0].Result.Allowed)
+
+	assert.Nil(t, results[1].Result)
+	assert.True(t, apperrors.IsValidationError(results[1].Err))
+
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, "RU", results[2].Result.Country)
+	assert.False(t, results[2].Result.Allowed)
+}
+
+func TestVerifyIPs_ExceedsMaxBatchSize(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{}
+	service := NewIPVerifierService(mockRepo, WithMaxBatchSize(2))
+	ctx := context.Background()
+
+	results, err := service.VerifyIPs(ctx, []string{"1.1.1.1", "8.8.8.8", "9.9.9.9"}, domain.Policy{AllowedCountries: []string{"US"}})
+
+	require.Error(t, err)
+	assert.Nil(t, results)
+	assert.True(t, apperrors.IsValidationError(err))
+	assert.Contains(t, err.Error(), "more than 2")
+}
+
+func TestVerifyIPs_EmptyInput(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{}
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	_, err := service.VerifyIPs(ctx, []string{}, domain.Policy{AllowedCountries: []string{"US"}})
+	assert.True(t, apperrors.IsValidationError(err))
+
+	_, err = service.VerifyIPs(ctx, []string{"8.8.8.8"}, domain.Policy{})
+	assert.True(t, apperrors.IsValidationError(err))
+}
+
+func TestLookupIP(t *testing.T) {
+	mockRepo := &MockIPVerifierRepo{
+		GetCountryByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Location, error) {
+			switch ipAddress {
+			case "5.5.5.5":
+				return &domain.Location{Country: "NL", Continent: "EU"}, nil
+			case "not-an-ip":
+				return nil, apperrors.NewValidationError("Invalid IP address", nil)
+			default:
+				return nil, apperrors.NewNotFoundError("No country found for IP address", nil)
+			}
+		},
+		GetASNByIPFunc: func(ctx context.Context, ipAddress string) (*domain.ASN, error) {
+			return &domain.ASN{Number: 64500, Organization: "HOSTING"}, nil
+		},
+		GetAnonymityByIPFunc: func(ctx context.Context, ipAddress string) (*domain.Anonymity, error) {
+			return &domain.Anonymity{IsAnonymous: true, IsVPN: true}, nil
+		},
+	}
+
+	service := NewIPVerifierService(mockRepo)
+	ctx := context.Background()
+
+	result, err := service.LookupIP(ctx, "5.5.5.5")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.LookupResult{
+		IP:        "5.5.5.5",
+		Location:  &domain.Location{Country: "NL", Continent: "EU"},
+		ASN:       &domain.ASN{Number: 64500, Organization: "HOSTING"},
+		Anonymity: &domain.Anonymity{IsAnonymous: true, IsVPN: true},
+	}, result)
+
+	// A missing country is not an error
+	result, err = service.LookupIP(ctx, "9.9.9.9")
+	require.NoError(t, err)
+	assert.Nil(t, result.Location)
+	assert.Equal(t, uint(64500), result.ASN.Number)
+
+	// Special-purpose ranges are reported without lookups
+	result, err = service.LookupIP(ctx, "10.1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.LookupResult{IP: "10.1.2.3", RangeType: domain.RangePrivate}, result)
+
+	_, err = service.LookupIP(ctx, "not-an-ip")
+	require.Error(t, err)
+	assert.True(t, apperrors.IsValidationError(err))
+}
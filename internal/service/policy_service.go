@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"regexp"
+	"sync"
+)
+
+// policyIDPattern restricts policy IDs to URL-safe slugs
+var policyIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type policyService struct {
+	repo     domain.PolicyRepo
+	compiler *PolicyCompiler
+	// mu orders writes, so the compiler holds the last stored version of
+	// each policy
+	mu sync.Mutex
+}
+
+// NewPolicyService creates a new instance of PolicyService. Policies are
+// compiled into compiler as they are stored.
+func NewPolicyService(repo domain.PolicyRepo, compiler *PolicyCompiler) domain.PolicyService {
+	return &policyService{
+		repo:     repo,
+		compiler: compiler,
+	}
+}
+
+// ListPolicies returns all stored policies
+func (s *policyService) ListPolicies(ctx context.Context) ([]domain.Policy, error) {
+	return s.repo.List(ctx)
+}
+
+// GetPolicy retrieves a policy by ID
+func (s *policyService) GetPolicy(ctx context.Context, id string) (*domain.Policy, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// CreatePolicy validates and stores a new policy
+func (s *policyService) CreatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	normalized, err := normalizePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := s.compiler.build(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.repo.Create(ctx, normalized); err != nil {
+		return nil, err
+	}
+	s.compiler.store(normalized.ID, compiled)
+	return &normalized, nil
+}
+
+// UpdatePolicy validates and replaces an existing policy
+func (s *policyService) UpdatePolicy(ctx context.Context, policy domain.Policy) (*domain.Policy, error) {
+	normalized, err := normalizePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := s.compiler.build(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.repo.Update(ctx, normalized); err != nil {
+		return nil, err
+	}
+	s.compiler.store(normalized.ID, compiled)
+	return &normalized, nil
+}
+
+// DeletePolicy removes a policy by ID
+func (s *policyService) DeletePolicy(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.compiler.remove(id)
+	return nil
+}
+
+// normalizePolicy validates a named policy and upper-cases its country codes
+func normalizePolicy(policy domain.Policy) (domain.Policy, error) {
+	if !policyIDPattern.MatchString(policy.ID) {
+		return domain.Policy{}, apperrors.NewValidationError(
+			"Policy id must be 1-64 lowercase letters, digits, '-' or '_'", nil)
+	}
+
+	allowed, err := normalizeCountryCodes("allowed_countries", policy.AllowedCountries)
+	if err != nil {
+		return domain.Policy{}, err
+	}
+	denied, err := normalizeCountryCodes("denied_countries", policy.DeniedCountries)
+	if err != nil {
+		return domain.Policy{}, err
+	}
+
+	allowedCIDRs, err := normalizeCIDRs("allowed_cidrs", policy.AllowedCIDRs)
+	if err != nil {
+		return domain.Policy{}, err
+	}
+	deniedCIDRs, err := normalizeCIDRs("denied_cidrs", policy.DeniedCIDRs)
+	if err != nil {
+		return domain.Policy{}, err
+	}
+
+	policy.AllowedCountries = allowed
+	policy.DeniedCountries = denied
+	policy.AllowedCIDRs = allowedCIDRs
+	policy.DeniedCIDRs = deniedCIDRs
+	if err := validatePolicy(policy); err != nil {
+		return domain.Policy{}, err
+	}
+	return policy, nil
+}
+
+// normalizeCountryCodes puts list entries in canonical form and rejects
+// country codes that are not ISO 3166-1 alpha-2
+func normalizeCountryCodes(field string, entries []string) ([]string, error) {
+	rules, err := parseNamedRules(field, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]string, 0, len(rules))
+	for _, r := range rules {
+		normalized = append(normalized, r.String())
+	}
+	return normalized, nil
+}
+
+// normalizeCIDRs puts CIDR entries in canonical form with host bits masked off
+func normalizeCIDRs(field string, entries []string) ([]string, error) {
+	if entries == nil {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := parseCIDR(field, entry)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, prefix.String())
+	}
+	return normalized, nil
+}
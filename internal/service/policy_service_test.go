@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPolicyRepo is a mock implementation of domain.PolicyRepo
+type MockPolicyRepo struct {
+	ListFunc   func(ctx context.Context) ([]domain.Policy, error)
+	GetFunc    func(ctx context.Context, id string) (*domain.Policy, error)
+	CreateFunc func(ctx context.Context, policy domain.Policy) error
+	UpdateFunc func(ctx context.Context, policy domain.Policy) error
+	DeleteFunc func(ctx context.Context, id string) error
+}
+
+func (m *MockPolicyRepo) List(ctx context.Context) ([]domain.Policy, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockPolicyRepo) Get(ctx context.Context, id string) (*domain.Policy, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, id)
+	}
+	return nil, apperrors.NewNotFoundError("Policy not found", nil)
+}
+
+func (m *MockPolicyRepo) Create(ctx context.Context, policy domain.Policy) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, policy)
+	}
+	return nil
+}
+
+func (m *MockPolicyRepo) Update(ctx context.Context, policy domain.Policy) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, policy)
+	}
+	return nil
+}
+
+func (m *MockPolicyRepo) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func TestCreatePolicy_NormalizesCountryCodes(t *testing.T) {
+	var stored domain.Policy
+	mockRepo := &MockPolicyRepo{
+		CreateFunc: func(ctx context.Context, policy domain.Policy) error {
+			stored = policy
+			return nil
+		},
+	}
+
+	service := NewPolicyService(mockRepo, NewPolicyCompiler())
+	ctx := context.Background()
+
+	policy, err := service.CreatePolicy(ctx, domain.Policy{
+		ID:               "north-america",
+		AllowedCountries: []string{"us", " ca ", "Group:Schengen", "CONTINENT:oc", "ca-qc"},
+		DeniedCountries:  []string{"kp"},
+		DeniedCIDRs:      []string{"10.1.2.3/8", "2001:DB8::1"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"US", "CA", "group:SCHENGEN", "continent:OC", "CA-QC"}, policy.AllowedCountries)
+	assert.Equal(t, []string{"KP"}, policy.DeniedCountries)
+	assert.Equal(t, []string{"10.0.0.0/8", "2001:db8::1/128"}, policy.DeniedCIDRs)
+	assert.Equal(t, *policy, stored)
+}
+
+func TestCreatePolicy_Validation(t *testing.T) {
+	service := NewPolicyService(&MockPolicyRepo{}, NewPolicyCompiler())
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		policy  domain.Policy
+		message string
+	}{
+		{"missing id", domain.Policy{AllowedCountries: []string{"US"}}, "Policy id"},
+		{"invalid id", domain.Policy{ID: "North America", AllowedCountries: []string{"US"}}, "Policy id"},
+		{"no rules", domain.Policy{ID: "empty"}, "must be provided"},
+		{"unknown allowed code", domain.Policy{ID: "bad", AllowedCountries: []string{"USA"}}, `allowed_countries contains invalid ISO country code "USA"`},
+		{"unknown denied code", domain.Policy{ID: "bad", DeniedCountries: []string{"ZZ"}}, `denied_countries contains invalid ISO country code "ZZ"`},
+		{"non-ISO united kingdom", domain.Policy{ID: "bad", DeniedCountries: []string{"UK"}}, `denied_countries contains invalid ISO country code "UK"`},
+		{"unknown subdivision country", domain.Policy{ID: "bad", AllowedCountries: []string{"XX-CA"}}, `allowed_countries contains invalid ISO country code "XX-CA"`},
+		{"malformed subdivision", domain.Policy{ID: "bad", DeniedCountries: []string{"US-"}}, `denied_countries contains invalid subdivision code "US-"`},
+		{"invalid cidr", domain.Policy{ID: "bad", AllowedCIDRs: []string{"10.0.0.0/33"}}, `allowed_cidrs contains invalid CIDR "10.0.0.0/33"`},
+		{"unknown group", domain.Policy{ID: "bad", AllowedCountries: []string{"group:NATO"}}, `allowed_countries contains unknown group "NATO"`},
+		{"unknown continent", domain.Policy{ID: "bad", DeniedCountries: []string{"continent:XX"}}, `denied_countries contains unknown continent "XX"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := service.CreatePolicy(ctx, tt.policy)
+			require.Error(t, err)
+			assert.Nil(t, policy)
+			assert.True(t, apperrors.IsValidationError(err))
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestUpdatePolicy_NotFound(t *testing.T) {
+	mockRepo := &MockPolicyRepo{
+		UpdateFunc: func(ctx context.Context, policy domain.Policy) error {
+			return apperrors.NewNotFoundError("Policy not found", nil)
+		},
+	}
+
+	service := NewPolicyService(mockRepo, NewPolicyCompiler())
+	ctx := context.Background()
+
+	policy, err := service.UpdatePolicy(ctx, domain.Policy{ID: "missing", AllowedCountries: []string{"US"}})
+
+	require.Error(t, err)
+	assert.Nil(t, policy)
+	assert.True(t, apperrors.IsNotFoundError(err))
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"net/netip"
+)
+
+type specialRange struct {
+	prefix    netip.Prefix
+	rangeType domain.RangeType
+}
+
+// specialRanges lists the IANA IPv4 and IPv6 special-purpose address blocks
+// that are not globally reachable, plus the multicast and reserved bogon
+// space. Globally reachable entries of the registries (AS112, 6to4, Teredo,
+// NAT64) are deliberately omitted so they are still geolocated.
+//
+// More specific prefixes must come before the blocks that contain them. An
+// empty range type carves a globally reachable block out of the one after it.
+var specialRanges = []specialRange{
+	// IPv4 (RFC 6890 and successors)
+	{netip.MustParsePrefix("0.0.0.0/8"), domain.RangeThisNetwork},
+	{netip.MustParsePrefix("10.0.0.0/8"), domain.RangePrivate},
+	{netip.MustParsePrefix("100.64.0.0/10"), domain.RangeCGNAT},
+	{netip.MustParsePrefix("127.0.0.0/8"), domain.RangeLoopback},
+	{netip.MustParsePrefix("169.254.0.0/16"), domain.RangeLinkLocal},
+	{netip.MustParsePrefix("172.16.0.0/12"), domain.RangePrivate},
+	{netip.MustParsePrefix("192.0.0.9/32"), ""},  // Port Control Protocol anycast
+	{netip.MustParsePrefix("192.0.0.10/32"), ""}, // TURN anycast
+	{netip.MustParsePrefix("192.0.0.0/24"), domain.RangeReserved},
+	{netip.MustParsePrefix("192.0.2.0/24"), domain.RangeDocumentation},
+	{netip.MustParsePrefix("192.88.99.0/24"), domain.RangeReserved},
+	{netip.MustParsePrefix("192.168.0.0/16"), domain.RangePrivate},
+	{netip.MustParsePrefix("198.18.0.0/15"), domain.RangeBenchmarking},
+	{netip.MustParsePrefix("198.51.100.0/24"), domain.RangeDocumentation},
+	{netip.MustParsePrefix("203.0.113.0/24"), domain.RangeDocumentation},
+	{netip.MustParsePrefix("224.0.0.0/4"), domain.RangeMulticast},
+	{netip.MustParsePrefix("255.255.255.255/32"), domain.RangeBroadcast},
+	{netip.MustParsePrefix("240.0.0.0/4"), domain.RangeReserved},
+
+	// IPv6 (RFC 6890 and successors)
+	{netip.MustParsePrefix("::/128"), domain.RangeUnspecified},
+	{netip.MustParsePrefix("::1/128"), domain.RangeLoopback},
+	{netip.MustParsePrefix("64:ff9b:1::/48"), domain.RangeReserved},
+	{netip.MustParsePrefix("100::/64"), domain.RangeReserved},
+	{netip.MustParsePrefix("2001:2::/48"), domain.RangeBenchmarking},
+	{netip.MustParsePrefix("2001:10::/28"), domain.RangeReserved},
+	{netip.MustParsePrefix("2001:db8::/32"), domain.RangeDocumentation},
+	{netip.MustParsePrefix("3fff::/20"), domain.RangeDocumentation},
+	{netip.MustParsePrefix("5f00::/16"), domain.RangeReserved},
+	{netip.MustParsePrefix("fc00::/7"), domain.RangeUniqueLocal},
+	{netip.MustParsePrefix("fe80::/10"), domain.RangeLinkLocal},
+	{netip.MustParsePrefix("ff00::/8"), domain.RangeMulticast},
+}
+
+// globalUnicast is the only IPv6 space currently allocated for public use
+var globalUnicast = netip.MustParsePrefix("2000::/3")
+
+// classifyIP returns the special-purpose range an address belongs to, or an
+// empty RangeType for ordinary public addresses.
+func classifyIP(addr netip.Addr) domain.RangeType {
+	addr = addr.Unmap().WithZone("")
+
+	for _, r := range specialRanges {
+		if r.prefix.Contains(addr) {
+			return r.rangeType
+		}
+	}
+
+	// IPv6 outside 2000::/3 that is not listed above is unallocated (bogon)
+	if addr.Is6() && !globalUnicast.Contains(addr) {
+		return domain.RangeReserved
+	}
+	return ""
+}
+
+// isInternalRange reports whether a range carries internal traffic that a
+// policy may choose to always allow
+func isInternalRange(rangeType domain.RangeType) bool {
+	switch rangeType {
+	case domain.RangePrivate, domain.RangeCGNAT, domain.RangeLoopback,
+		domain.RangeLinkLocal, domain.RangeUniqueLocal:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected domain.RangeType
+	}{
+		{"10.0.0.5", domain.RangePrivate},
+		{"172.31.255.255", domain.RangePrivate},
+		{"192.168.1.1", domain.RangePrivate},
+		{"100.64.0.1", domain.RangeCGNAT},
+		{"100.127.255.255", domain.RangeCGNAT},
+		{"127.0.0.1", domain.RangeLoopback},
+		{"169.254.169.254", domain.RangeLinkLocal},
+		{"192.0.0.8", domain.RangeReserved},
+		{"192.0.0.170", domain.RangeReserved},
+		{"192.0.2.10", domain.RangeDocumentation},
+		{"198.51.100.7", domain.RangeDocumentation},
+		{"203.0.113.99", domain.RangeDocumentation},
+		{"198.18.0.1", domain.RangeBenchmarking},
+		{"224.0.0.251", domain.RangeMulticast},
+		{"255.255.255.255", domain.RangeBroadcast},
+		{"240.0.0.1", domain.RangeReserved},
+		{"0.1.2.3", domain.RangeThisNetwork},
+		{"::", domain.RangeUnspecified},
+		{"::1", domain.RangeLoopback},
+		{"::ffff:10.1.2.3", domain.RangePrivate},
+		{"fd12:3456::1", domain.RangeUniqueLocal},
+		{"fe80::1%eth0", domain.RangeLinkLocal},
+		{"2001:db8::1", domain.RangeDocumentation},
+		{"ff02::1", domain.RangeMulticast},
+		{"4000::1", domain.RangeReserved},
+
+		// Publicly routable addresses are not classified
+		{"8.8.8.8", ""},
+		{"100.128.0.1", ""},
+		{"172.32.0.1", ""},
+		{"2001:4860:4860::8888", ""},
+		{"2002:808:808::1", ""},
+		{"192.0.0.9", ""},
+		{"192.0.0.10", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifyIP(netip.MustParseAddr(tt.ip)))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	continentPrefix = "continent:"
+	groupPrefix     = "group:"
+)
+
+// ruleKind identifies what a policy list entry refers to
+type ruleKind int
+
+const (
+	ruleCountry ruleKind = iota
+	ruleContinent
+	ruleGroup
+	ruleSubdivision
+)
+
+// subdivisionPattern matches ISO 3166-2 codes such as "US-CA" or "CA-QC"
+var subdivisionPattern = regexp.MustCompile(`^[A-Z]{2}-[A-Z0-9]{1,3}$`)
+
+// rule is a parsed policy list entry
+type rule struct {
+	kind  ruleKind
+	value string // upper-cased code, subdivision code or group name
+}
+
+// String formats the rule in its canonical list-entry form
+func (r rule) String() string {
+	switch r.kind {
+	case ruleContinent:
+		return continentPrefix + r.value
+	case ruleGroup:
+		return groupPrefix + r.value
+	default:
+		return r.value
+	}
+}
+
+// parseRule parses a list entry such as "US", "US-CA", "continent:EU" or
+// "group:SCHENGEN".
+// Country codes are not checked against ISO 3166 here, so inline requests
+// keep accepting any code; named policies are parsed with parseNamedRules.
+func parseRule(field, entry string) (rule, error) {
+	entry = strings.TrimSpace(entry)
+	lower := strings.ToLower(entry)
+
+	switch {
+	case strings.HasPrefix(lower, continentPrefix):
+		code := strings.ToUpper(entry[len(continentPrefix):])
+		if _, ok := continentCodes[code]; !ok {
+			return rule{}, apperrors.NewValidationError(
+				fmt.Sprintf("%s contains unknown continent %q", field, code), nil)
+		}
+		return rule{kind: ruleContinent, value: code}, nil
+
+	case strings.HasPrefix(lower, groupPrefix):
+		name := strings.ToUpper(entry[len(groupPrefix):])
+		if _, ok := countryGroups[name]; !ok {
+			return rule{}, apperrors.NewValidationError(
+				fmt.Sprintf("%s contains unknown group %q", field, name), nil)
+		}
+		return rule{kind: ruleGroup, value: name}, nil
+
+	case strings.Contains(entry, "-"):
+		code := strings.ToUpper(entry)
+		if !subdivisionPattern.MatchString(code) {
+			return rule{}, apperrors.NewValidationError(
+				fmt.Sprintf("%s contains invalid subdivision code %q", field, code), nil)
+		}
+		return rule{kind: ruleSubdivision, value: code}, nil
+
+	default:
+		return rule{kind: ruleCountry, value: strings.ToUpper(entry)}, nil
+	}
+}
+
+// country returns the country code of a country or subdivision rule
+func (r rule) country() string {
+	country, _, _ := strings.Cut(r.value, "-")
+	return country
+}
+
+// parseRules parses every entry of a policy list
+func parseRules(field string, entries []string) ([]rule, error) {
+	rules := make([]rule, 0, len(entries))
+	for _, entry := range entries {
+		r, err := parseRule(field, entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// parseNamedRules parses the entries of a named policy list, which must
+// also use ISO 3166-1 alpha-2 country codes (e.g., "GB" rather than "UK")
+func parseNamedRules(field string, entries []string) ([]rule, error) {
+	rules, err := parseRules(field, entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.kind != ruleCountry && r.kind != ruleSubdivision {
+			continue
+		}
+		if _, ok := isoCountryCodes[r.country()]; !ok {
+			return nil, apperrors.NewValidationError(
+				fmt.Sprintf("%s contains invalid ISO country code %q", field, r.value), nil)
+		}
+	}
+	return rules, nil
+}
+
+// matches reports whether the rule covers the location
+func (r rule) matches(location *domain.Location) bool {
+	switch r.kind {
+	case ruleContinent:
+		return location.Continent == r.value
+	case ruleGroup:
+		return slices.Contains(countryGroups[r.value], location.Country)
+	case ruleSubdivision:
+		country, subdivision, _ := strings.Cut(r.value, "-")
+		return location.Country == country && slices.Contains(location.Subdivisions, subdivision)
+	default:
+		return location.Country == r.value
+	}
+}
+
+// matchRules returns the first rule that covers the location
+func matchRules(rules []rule, location *domain.Location) (rule, bool) {
+	for _, r := range rules {
+		if r.matches(location) {
+			return r, true
+		}
+	}
+	return rule{}, false
+}
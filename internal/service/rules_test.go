@@ -0,0 +1,90 @@
+package service
+
+import (
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		entry    string
+		expected rule
+	}{
+		{"US", rule{kind: ruleCountry, value: "US"}},
+		{" us ", rule{kind: ruleCountry, value: "US"}},
+		{"continent:eu", rule{kind: ruleContinent, value: "EU"}},
+		{"Continent:AF", rule{kind: ruleContinent, value: "AF"}},
+		{"group:schengen", rule{kind: ruleGroup, value: "SCHENGEN"}},
+		{"GROUP:EEA", rule{kind: ruleGroup, value: "EEA"}},
+		{"us-ca", rule{kind: ruleSubdivision, value: "US-CA"}},
+		{"CA-QC", rule{kind: ruleSubdivision, value: "CA-QC"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			r, err := parseRule("allowed_countries", tt.entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestParseNamedRules(t *testing.T) {
+	tests := []struct {
+		entry string
+		valid bool
+	}{
+		{"GB", true},
+		{"gb-eng", true},
+		{"group:EU", true},
+		{"UK", false},
+		{"UK-ENG", false},
+		{"XX", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			// Inline policies accept any two-letter code
+			_, err := parseRules("allowed_countries", []string{tt.entry})
+			require.NoError(t, err)
+
+			_, err = parseNamedRules("allowed_countries", []string{tt.entry})
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "invalid ISO country code")
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	germany := &domain.Location{Country: "DE", Continent: "EU"}
+	switzerland := &domain.Location{Country: "CH", Continent: "EU"}
+
+	assert.True(t, rule{kind: ruleCountry, value: "DE"}.matches(germany))
+	assert.True(t, rule{kind: ruleContinent, value: "EU"}.matches(switzerland))
+	assert.True(t, rule{kind: ruleGroup, value: "EU"}.matches(germany))
+	assert.False(t, rule{kind: ruleGroup, value: "EU"}.matches(switzerland))
+	assert.True(t, rule{kind: ruleGroup, value: "SCHENGEN"}.matches(switzerland))
+	assert.False(t, rule{kind: ruleGroup, value: "EEA"}.matches(switzerland))
+
+	california := &domain.Location{Country: "US", Subdivisions: []string{"CA"}}
+	quebec := &domain.Location{Country: "CA", Subdivisions: []string{"QC"}}
+	assert.True(t, rule{kind: ruleSubdivision, value: "US-CA"}.matches(california))
+	assert.False(t, rule{kind: ruleSubdivision, value: "CA-QC"}.matches(california))
+	assert.True(t, rule{kind: ruleSubdivision, value: "CA-QC"}.matches(quebec))
+	assert.False(t, rule{kind: ruleSubdivision, value: "US-CA"}.matches(&domain.Location{Country: "US"}))
+}
+
+func TestCountryGroupsUseISOCodes(t *testing.T) {
+	for name, members := range countryGroups {
+		for _, code := range members {
+			_, ok := isoCountryCodes[code]
+			assert.True(t, ok, "group %s contains unknown code %s", name, code)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package tracing sets up OpenTelemetry tracing: W3C trace context
+// propagation and export of spans to an OTLP collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config selects where spans are exported
+type Config struct {
+	Endpoint    string // OTLP/gRPC collector URL (e.g., "http://otel-collector:4317"); empty disables export
+	ServiceName string // service.name resource attribute
+}
+
+// Setup installs the W3C trace context propagator and, when an endpoint is
+// configured, a tracer provider exporting to it. Without an endpoint spans
+// are not recorded, but incoming trace context is still passed on. The
+// returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(cfg.ServiceName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// EndSpan records err, if any, on span and ends it. Only internal errors mark
+// the span as failed; validation and not found errors are part of normal
+// operation (e.g., a private IP has no country).
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if apperrors.GetHTTPStatus(err) >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, apperrors.GetMessage(err))
+		}
+	}
+	span.End()
+}
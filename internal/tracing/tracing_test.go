@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// collector is an in-process stand-in for an OTLP/gRPC collector
+type collector struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	mu    sync.Mutex
+	spans []*tracepb.ResourceSpans
+}
+
+func (c *collector) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, req.GetResourceSpans()...)
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) resourceSpans() []*tracepb.ResourceSpans {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.spans
+}
+
+func startCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	c := &collector{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, c)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return c, "http://" + listener.Addr().String()
+}
+
+func TestSetup_ExportsToCollector(t *testing.T) {
+	c, endpoint := startCollector(t)
+
+	shutdown, err := Setup(t.Context(), Config{Endpoint: endpoint, ServiceName: "ip-verifier-test"})
+	require.NoError(t, err)
+
+	// Continue the trace of an incoming request
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(t.Context(), propagation.HeaderCarrier(header))
+	_, span := otel.Tracer("test").Start(ctx, "handler.VerifyIP")
+	span.End()
+
+	// Shutdown flushes the batched span
+	require.NoError(t, shutdown(t.Context()))
+
+	resourceSpans := c.resourceSpans()
+	require.Len(t, resourceSpans, 1)
+	var serviceName string
+	for _, attr := range resourceSpans[0].GetResource().GetAttributes() {
+		if attr.GetKey() == "service.name" {
+			serviceName = attr.GetValue().GetStringValue()
+		}
+	}
+	assert.Equal(t, "ip-verifier-test", serviceName)
+
+	require.Len(t, resourceSpans[0].GetScopeSpans(), 1)
+	spans := resourceSpans[0].GetScopeSpans()[0].GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "handler.VerifyIP", spans[0].GetName())
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	parentID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	assert.Equal(t, traceID[:], spans[0].GetTraceId())
+	assert.Equal(t, parentID[:], spans[0].GetParentSpanId())
+}
+
+func TestSetup_WithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(t.Context(), Config{ServiceName: "ip-verifier-test"})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(t.Context()))
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(t.Context(), propagation.HeaderCarrier(header))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+}
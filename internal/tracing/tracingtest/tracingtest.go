@@ -0,0 +1,64 @@
+// Package tracingtest records the spans of the global tracer provider so
+// tests can assert on the spans started by the code under test.
+package tracingtest
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	recorder = tracetest.NewSpanRecorder()
+	install  sync.Once
+)
+
+// Install sets a global tracer provider recording every span and the W3C
+// trace context propagator. Tracers obtained from the global provider only
+// delegate to the first provider set, so this happens once per test binary
+// and tests tell their spans apart by trace ID.
+func Install() {
+	install.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+}
+
+// NewParent returns ctx carrying a sampled remote span of a new trace, as if
+// the request came from a traced caller
+func NewParent(ctx context.Context) (context.Context, trace.SpanContext) {
+	Install()
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	for i := range traceID {
+		traceID[i] = byte(rand.IntN(256))
+	}
+	for i := range spanID {
+		spanID[i] = byte(rand.IntN(256))
+	}
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	return trace.ContextWithRemoteSpanContext(ctx, parent), parent
+}
+
+// Spans returns the ended spans of the trace in the order they ended
+func Spans(traceID trace.TraceID) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == traceID {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
@@ -0,0 +1,240 @@
+// Package client is a Go client for the ip-verifier HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries after a failed attempt unless overridden
+	DefaultMaxRetries = 3
+	// DefaultBackoff is the delay before the first retry unless overridden
+	DefaultBackoff = 100 * time.Millisecond
+
+	// maxBackoff caps the delay between retries
+	maxBackoff = 5 * time.Second
+	// maxErrorBody limits how much of an error response is read
+	maxErrorBody = 1 << 20
+)
+
+// Client calls the ip-verifier HTTP API. It is safe for concurrent use.
+//
+// Requests that fail with a network error or a 5xx status (including a 503
+// from the health endpoint) are retried with exponential backoff and jitter
+// until the retries are exhausted or the context is done. All API calls are
+// free of side effects, so retrying them is safe.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option configures optional behaviour of the client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests (default http.DefaultClient)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithMaxRetries sets how many times a failed request is retried; 0 disables retries
+func WithMaxRetries(n int) Option {
+	return func(c *Client) {
+		c.maxRetries = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry. It doubles with each
+// further retry, up to 5s.
+func WithBackoff(d time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = d
+	}
+}
+
+// New creates a client for the API at baseURL (e.g., "http://ip-verifier:8080")
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("ip-verifier: invalid base URL %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimSuffix(u.String(), "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Verify checks one IP against a named or inline policy
+func (c *Client) Verify(ctx context.Context, req VerifyRequest) (*VerifyResponse, error) {
+	var resp VerifyResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/ip-verifier", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BatchVerify checks several IPs against the same policy. IPs that cannot be
+// verified are reported in their item's Error field.
+func (c *Client) BatchVerify(ctx context.Context, req BatchVerifyRequest) (*BatchVerifyResponse, error) {
+	var resp BatchVerifyResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/ip-verifier/batch", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Lookup returns the data known about ip without applying a policy
+func (c *Client) Lookup(ctx context.Context, ip string) (*LookupResponse, error) {
+	var resp LookupResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/ip-verifier/lookup/"+url.PathEscape(ip), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Health checks the service. An unhealthy service is reported as an *Error
+// with code 503 once the retries are exhausted.
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var resp HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/health", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// do sends a request, retrying temporary failures, and decodes the response
+// into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("ip-verifier: encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, method, path, payload, out)
+		if err == nil || !retryable(err) || attempt >= c.maxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(c.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends a single request
+func (c *Client) attempt(ctx context.Context, method, path string, payload []byte, out any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("ip-verifier: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ip-verifier: decode response: %w", err)
+	}
+	return nil
+}
+
+// delay returns the backoff before retry attempt+1: the base delay doubled
+// per attempt, capped, with the upper half randomised
+func (c *Client) delay(attempt int) time.Duration {
+	d := c.backoff << attempt
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// decodeError reads the API's {"error": message, "request_id": id} body. The
+// health endpoint reports failures in a "message" field instead, and the
+// request ID is also taken from the X-Request-ID header of every response.
+func decodeError(resp *http.Response) *Error {
+	var body struct {
+		Error     string `json:"error"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_ = json.Unmarshal(data, &body)
+
+	message := body.Error
+	if message == "" {
+		message = body.Message
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	requestID := resp.Header.Get("X-Request-ID")
+	if requestID == "" {
+		requestID = body.RequestID
+	}
+	return &Error{Code: resp.StatusCode, Message: message, RequestID: requestID}
+}
+
+// transportError marks a request that failed before a response was received
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return "ip-verifier: " + e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryable reports whether err may go away on retry
+func retryable(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var transportErr *transportError
+	return errors.As(err, &transportErr)
+}
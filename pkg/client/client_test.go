@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, append([]Option{WithBackoff(time.Millisecond)}, opts...)...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestVerify(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/ip-verifier", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{
+			"ip":                "8.8.8.8",
+			"allowed_countries": []any{"US"},
+			"denied_asns":       []any{float64(64500)},
+		}, body)
+
+		w.Write([]byte(`{
+			"ip": "8.8.8.8",
+			"country": "US",
+			"coordinates": {"latitude": 37.4, "longitude": -122.1, "accuracy_radius_km": 100},
+			"asn": 15169,
+			"allowed": true,
+			"reason": "country_allowed",
+			"matched_rule": {"list": "allowed_countries", "entry": "US"},
+			"database_build": "2026-10-06T00:00:00Z"
+		}`))
+	})
+
+	resp, err := c.Verify(context.Background(), VerifyRequest{
+		IP:     "8.8.8.8",
+		Policy: Policy{AllowedCountries: []string{"US"}, DeniedASNs: []uint{64500}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &VerifyResponse{
+		IP:            "8.8.8.8",
+		Country:       "US",
+		Coordinates:   &Coordinates{Latitude: 37.4, Longitude: -122.1, AccuracyRadiusKm: 100},
+		ASN:           15169,
+		Allowed:       true,
+		Reason:        "country_allowed",
+		MatchedRule:   &MatchedRule{List: "allowed_countries", Entry: "US"},
+		DatabaseBuild: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC),
+	}, resp)
+}
+
+func TestBatchVerify(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/ip-verifier/batch", r.URL.Path)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "eu-only", body["policy_id"])
+
+		w.Write([]byte(`{"results": [
+			{"ip": "8.8.8.8", "country": "US", "allowed": false, "reason": "not_in_allowlist"},
+			{"ip": "not-an-ip", "allowed": false, "error": "Invalid IP address"}
+		]}`))
+	})
+
+	resp, err := c.BatchVerify(context.Background(), BatchVerifyRequest{
+		IPs:    []string{"8.8.8.8", "not-an-ip"},
+		Policy: Policy{PolicyID: "eu-only"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "US", resp.Results[0].Country)
+	assert.Empty(t, resp.Results[0].Error)
+	assert.Equal(t, "Invalid IP address", resp.Results[1].Error)
+}
+
+func TestLookup(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/ip-verifier/lookup/2001:db8::1", r.URL.Path)
+		w.Write([]byte(`{"ip": "2001:db8::1", "range_type": "documentation"}`))
+	})
+
+	resp, err := c.Lookup(context.Background(), "2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, &LookupResponse{IP: "2001:db8::1", RangeType: "documentation"}, resp)
+}
+
+func TestHealth(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/health", r.URL.Path)
+		w.Write([]byte(`{"status": "healthy"}`))
+	})
+
+	resp, err := c.Health(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", resp.Status)
+}
+
+func TestHealth_UnavailableAfterRetries(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status": "unhealthy", "message": "GeoIP database unavailable"}`))
+	}, WithMaxRetries(2))
+
+	_, err := c.Health(context.Background())
+	require.Error(t, err)
+	assert.True(t, IsUnavailableError(err))
+	assert.Equal(t, "GeoIP database unavailable", err.(*Error).Message)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRetry_RecoversFromServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body VerifyRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "8.8.8.8", body.IP, "body is resent on retry")
+
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Failed to lookup IP address"}`))
+			return
+		}
+		w.Write([]byte(`{"ip": "8.8.8.8", "allowed": true}`))
+	})
+
+	resp, err := c.Verify(context.Background(), VerifyRequest{IP: "8.8.8.8", Policy: Policy{AllowedCountries: []string{"US"}}})
+	require.NoError(t, err)
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestErrors_NotRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		check   func(error) bool
+		message string
+	}{
+		{"validation", http.StatusBadRequest, `{"error": "Invalid IP address"}`, IsValidationError, "Invalid IP address"},
+		{"not found", http.StatusNotFound, `{"error": "Policy not found"}`, IsNotFoundError, "Policy not found"},
+		{"non-JSON body", http.StatusUnauthorized, `nope`, IsUnauthorizedError, "Unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := c.Verify(context.Background(), VerifyRequest{IP: "x"})
+			require.Error(t, err)
+			assert.True(t, tt.check(err))
+
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.Code)
+			assert.Equal(t, tt.message, apiErr.Message)
+			assert.Equal(t, int32(1), attempts.Load())
+		})
+	}
+}
+
+func TestErrors_RequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		body     string
+		expected string
+	}{
+		{"header", "req-header", `{"error": "Invalid IP address", "request_id": "req-body"}`, "req-header"},
+		{"body", "", `{"error": "Invalid IP address", "request_id": "req-body"}`, "req-body"},
+		{"none", "", `{"error": "Invalid IP address"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set("X-Request-ID", tt.header)
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := c.Verify(context.Background(), VerifyRequest{IP: "x"})
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.expected, apiErr.RequestID)
+			if tt.expected != "" {
+				assert.Equal(t, "ip-verifier: 400 Invalid IP address (request ID "+tt.expected+")", err.Error())
+			} else {
+				assert.Equal(t, "ip-verifier: 400 Invalid IP address", err.Error())
+			}
+		})
+	}
+}
+
+func TestRetry_TransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	var attempts atomic.Int32
+	c, err := New(url, WithMaxRetries(2), WithBackoff(time.Millisecond),
+		WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return http.DefaultTransport.RoundTrip(r)
+		})}))
+	require.NoError(t, err)
+
+	_, err = c.Health(context.Background())
+	require.Error(t, err)
+	assert.False(t, IsUnavailableError(err))
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRetry_StopsWhenContextDone(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}, WithMaxRetries(10), WithBackoff(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Health(ctx)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, err.(*Error).Code)
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "ip-verifier:8080", "://nope"} {
+		_, err := New(baseURL)
+		assert.Error(t, err, baseURL)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error is an error response from the API. Code is the HTTP status code and
+// Message the "error" field of the body, matching the service's AppError.
+// RequestID identifies the request in the service's logs; quote it when
+// reporting a problem.
+type Error struct {
+	Code      int
+	Message   string
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("ip-verifier: %d %s (request ID %s)", e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("ip-verifier: %d %s", e.Code, e.Message)
+}
+
+// Temporary reports whether retrying the request may succeed
+func (e *Error) Temporary() bool {
+	return e.Code >= http.StatusInternalServerError
+}
+
+// IsValidationError checks if err is a 400 Bad Request from the API
+func IsValidationError(err error) bool {
+	return hasCode(err, http.StatusBadRequest)
+}
+
+// IsUnauthorizedError checks if err is a 401 Unauthorized from the API
+func IsUnauthorizedError(err error) bool {
+	return hasCode(err, http.StatusUnauthorized)
+}
+
+// IsNotFoundError checks if err is a 404 Not Found from the API
+func IsNotFoundError(err error) bool {
+	return hasCode(err, http.StatusNotFound)
+}
+
+// IsConflictError checks if err is a 409 Conflict from the API
+func IsConflictError(err error) bool {
+	return hasCode(err, http.StatusConflict)
+}
+
+// IsInternalError checks if err is a 500 Internal Server Error from the API
+func IsInternalError(err error) bool {
+	return hasCode(err, http.StatusInternalServerError)
+}
+
+// IsUnavailableError checks if err is a 503 Service Unavailable from the API
+func IsUnavailableError(err error) bool {
+	return hasCode(err, http.StatusServiceUnavailable)
+}
+
+func hasCode(err error, code int) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == code
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package client
+
+import "time"
+
+// Policy selects a named policy or holds inline rules. Set PolicyID or the
+// rule fields, not both; UnknownAction may override a named policy's action.
+type Policy struct {
+	PolicyID         string   `json:"policy_id,omitempty"`
+	AllowedCountries []string `json:"allowed_countries,omitempty"`
+	DeniedCountries  []string `json:"denied_countries,omitempty"`
+	AllowedASNs      []uint   `json:"allowed_asns,omitempty"`
+	DeniedASNs       []uint   `json:"denied_asns,omitempty"`
+	AllowedCIDRs     []string `json:"allowed_cidrs,omitempty"`
+	DeniedCIDRs      []string `json:"denied_cidrs,omitempty"`
+	DeniedAnonymity  []string `json:"denied_anonymity,omitempty"` // vpn, tor, hosting or public_proxy
+	UnknownAction    string   `json:"unknown_action,omitempty"`   // allow, deny or error
+	AllowInternal    bool     `json:"allow_internal,omitempty"`
+	MatchMode        string   `json:"match_mode,omitempty"` // physical, registered, any or all
+}
+
+// VerifyRequest is the body of POST /api/v1/ip-verifier
+type VerifyRequest struct {
+	IP string `json:"ip"`
+	Policy
+}
+
+// BatchVerifyRequest is the body of POST /api/v1/ip-verifier/batch
+type BatchVerifyRequest struct {
+	IPs []string `json:"ips"`
+	Policy
+}
+
+// Coordinates is the approximate position of an IP
+type Coordinates struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	AccuracyRadiusKm uint16  `json:"accuracy_radius_km,omitempty"`
+}
+
+// MatchedRule identifies the policy entry that decided a verification
+type MatchedRule struct {
+	List  string `json:"list"`
+	Entry string `json:"entry"`
+}
+
+// VerifyResponse is the result of verifying one IP
+type VerifyResponse struct {
+	IP                 string       `json:"ip"`
+	Country            string       `json:"country,omitempty"`
+	Continent          string       `json:"continent,omitempty"`
+	RegisteredCountry  string       `json:"registered_country,omitempty"`
+	RepresentedCountry string       `json:"represented_country,omitempty"`
+	Subdivisions       []string     `json:"subdivisions,omitempty"`
+	City               string       `json:"city,omitempty"`
+	PostalCode         string       `json:"postal_code,omitempty"`
+	Coordinates        *Coordinates `json:"coordinates,omitempty"`
+	ASN                uint         `json:"asn,omitempty"`
+	ASOrg              string       `json:"as_org,omitempty"`
+	IsVPN              bool         `json:"is_vpn,omitempty"`
+	IsTor              bool         `json:"is_tor,omitempty"`
+	IsHosting          bool         `json:"is_hosting,omitempty"`
+	IsPublicProxy      bool         `json:"is_public_proxy,omitempty"`
+	Allowed            bool         `json:"allowed"`
+	Reason             string       `json:"reason,omitempty"`
+	RangeType          string       `json:"range_type,omitempty"`
+	MatchedGroup       string       `json:"matched_group,omitempty"`
+	MatchedRule        *MatchedRule `json:"matched_rule,omitempty"`
+	DatabaseBuild      time.Time    `json:"database_build,omitzero"`
+}
+
+// BatchVerifyItem is the result for one IP of a batch. Error is set instead
+// of the verification fields when the IP could not be verified.
+type BatchVerifyItem struct {
+	VerifyResponse
+	Error string `json:"error,omitempty"`
+}
+
+// BatchVerifyResponse holds batch results in request order
+type BatchVerifyResponse struct {
+	Results []BatchVerifyItem `json:"results"`
+}
+
+// LookupResponse is the data known about an IP, without a policy decision
+type LookupResponse struct {
+	IP                 string       `json:"ip"`
+	Country            string       `json:"country,omitempty"`
+	Continent          string       `json:"continent,omitempty"`
+	RegisteredCountry  string       `json:"registered_country,omitempty"`
+	RepresentedCountry string       `json:"represented_country,omitempty"`
+	Subdivisions       []string     `json:"subdivisions,omitempty"`
+	City               string       `json:"city,omitempty"`
+	PostalCode         string       `json:"postal_code,omitempty"`
+	Coordinates        *Coordinates `json:"coordinates,omitempty"`
+	ASN                uint         `json:"asn,omitempty"`
+	ASOrg              string       `json:"as_org,omitempty"`
+	IsAnonymous        bool         `json:"is_anonymous,omitempty"`
+	IsVPN              bool         `json:"is_vpn,omitempty"`
+	IsTor              bool         `json:"is_tor,omitempty"`
+	IsHosting          bool         `json:"is_hosting,omitempty"`
+	IsPublicProxy      bool         `json:"is_public_proxy,omitempty"`
+	RangeType          string       `json:"range_type,omitempty"`
+	DatabaseBuild      time.Time    `json:"database_build,omitzero"`
+}
+
+// HealthResponse is the body of GET /api/v1/health
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
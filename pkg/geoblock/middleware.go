@@ -0,0 +1,201 @@
+package geoblock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/KWilliams-dev/ip-verifier/internal/api/clientip"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPFunc returns the client IP of a request
+type ClientIPFunc func(r *http.Request) (netip.Addr, error)
+
+// DenyHandler writes the response for a request whose IP the policy denies
+type DenyHandler func(w http.ResponseWriter, r *http.Request, result *Result)
+
+// ErrorHandler writes the response for a request that could not be verified,
+// because the client IP could not be determined or the lookup failed
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Option configures the middleware
+type Option func(*middleware)
+
+// WithTrustedProxies honours the forwarding header set by proxies in the
+// given CIDRs or addresses, the same way the ip-verifier service does. The
+// header is X-Forwarded-For unless WithClientIPHeader says otherwise. Without
+// it only the connection's remote address is used. It is ignored when
+// WithClientIP is given.
+func WithTrustedProxies(proxies ...string) Option {
+	return func(m *middleware) {
+		m.trustedProxies = proxies
+	}
+}
+
+// WithClientIPHeader selects the forwarding header the trusted proxies set:
+// "Forwarded", "X-Forwarded-For" or "X-Real-IP". The other two are ignored,
+// as a client could send them through the proxies.
+func WithClientIPHeader(header string) Option {
+	return func(m *middleware) {
+		m.clientIPHeader = header
+	}
+}
+
+// WithClientIP replaces client IP resolution, for example to read a header
+// set by the caller's own edge
+func WithClientIP(fn ClientIPFunc) Option {
+	return func(m *middleware) {
+		m.clientIP = fn
+	}
+}
+
+// WithDenyHandler replaces the default 403 JSON response for denied requests
+func WithDenyHandler(fn DenyHandler) Option {
+	return func(m *middleware) {
+		m.deny = fn
+	}
+}
+
+// WithErrorHandler replaces the default JSON error response. Calling the
+// next handler from here is up to fn; the middleware never does.
+func WithErrorHandler(fn ErrorHandler) Option {
+	return func(m *middleware) {
+		m.onError = fn
+	}
+}
+
+type middleware struct {
+	verifier       *Verifier
+	policy         *service.CompiledPolicy
+	trustedProxies []string
+	clientIPHeader string
+	clientIP       ClientIPFunc
+	deny           DenyHandler
+	onError        ErrorHandler
+}
+
+type resultKey struct{}
+
+// ResultFromContext returns the verification result the middleware stored
+// in the request context of an allowed request
+func ResultFromContext(ctx context.Context) (*Result, bool) {
+	result, ok := ctx.Value(resultKey{}).(*Result)
+	return result, ok
+}
+
+func newMiddleware(v *Verifier, policy Policy, opts []Option) (*middleware, error) {
+	// The policy never changes, so it is compiled once rather than on every
+	// request
+	compiled, err := v.compiler.Compile(policy)
+	if err != nil {
+		return nil, fmt.Errorf("geoblock: %w", err)
+	}
+
+	m := &middleware{
+		verifier:       v,
+		policy:         compiled,
+		clientIPHeader: "X-Forwarded-For",
+		deny:           defaultDeny,
+		onError:        defaultError,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.clientIP == nil {
+		resolver, err := clientip.NewResolver(m.trustedProxies, clientip.Source(strings.ToLower(m.clientIPHeader)))
+		if err != nil {
+			return nil, fmt.Errorf("geoblock: %w", err)
+		}
+		m.clientIP = func(r *http.Request) (netip.Addr, error) {
+			addr, _, err := resolver.Resolve(r)
+			return addr, err
+		}
+	}
+	return m, nil
+}
+
+// verify returns the result for r, or nil after writing an error response
+func (m *middleware) verify(w http.ResponseWriter, r *http.Request) *Result {
+	addr, err := m.clientIP(r)
+	if err != nil {
+		m.onError(w, r, apperrors.NewValidationError(err.Error(), err))
+		return nil
+	}
+
+	result, err := m.verifier.service.VerifyCompiled(r.Context(), addr.String(), m.policy)
+	if err != nil {
+		m.onError(w, r, err)
+		return nil
+	}
+	return result
+}
+
+// Middleware returns net/http middleware that passes allowed requests to the
+// next handler and answers denied ones with the deny handler. The policy is
+// validated and compiled up front.
+func Middleware(v *Verifier, policy Policy, opts ...Option) (func(http.Handler) http.Handler, error) {
+	m, err := newMiddleware(v, policy, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result := m.verify(w, r)
+			if result == nil {
+				return
+			}
+			if !result.Allowed {
+				m.deny(w, r, result)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), resultKey{}, result)))
+		})
+	}, nil
+}
+
+// GinMiddleware is Middleware for Gin. Denied and failed requests are
+// aborted after the deny or error handler has written the response.
+func GinMiddleware(v *Verifier, policy Policy, opts ...Option) (gin.HandlerFunc, error) {
+	m, err := newMiddleware(v, policy, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		result := m.verify(c.Writer, c.Request)
+		if result == nil {
+			c.Abort()
+			return
+		}
+		if !result.Allowed {
+			m.deny(c.Writer, c.Request, result)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), resultKey{}, result))
+		c.Next()
+	}, nil
+}
+
+func defaultDeny(w http.ResponseWriter, r *http.Request, result *Result) {
+	writeJSONError(w, http.StatusForbidden, "Access denied")
+}
+
+func defaultError(w http.ResponseWriter, r *http.Request, err error) {
+	writeJSONError(w, apperrors.GetHTTPStatus(err), apperrors.GetMessage(err))
+}
+
+// writeJSONError renders the same {"error": message} body as the service
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,215 @@
+package geoblock
+
+import (
+	"context"
+	"errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	apperrors "github.com/KWilliams-dev/ip-verifier/internal/errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubService decides by IP: 8.8.8.8 is allowed, 77.88.8.8 denied and
+// anything else fails the lookup
+type stubService struct {
+	service.IPVerifierService
+}
+
+func (s stubService) VerifyCompiled(ctx context.Context, ip string, policy *service.CompiledPolicy) (*domain.VerifyResult, error) {
+	return s.VerifyIP(ctx, ip, domain.Policy{})
+}
+
+func (stubService) VerifyIP(ctx context.Context, ip string, policy domain.Policy) (*domain.VerifyResult, error) {
+	switch ip {
+	case "8.8.8.8":
+		return &domain.VerifyResult{IP: ip, Country: "US", Allowed: true, Reason: domain.ReasonCountryAllowed}, nil
+	case "77.88.8.8":
+		return &domain.VerifyResult{IP: ip, Country: "RU", Allowed: false, Reason: domain.ReasonNotInAllowlist}, nil
+	default:
+		return nil, apperrors.NewInternalError("Failed to lookup IP address", nil)
+	}
+}
+
+var usOnly = Policy{AllowedCountries: []string{"US"}}
+
+func newStubVerifier() *Verifier {
+	return &Verifier{service: stubService{}, compiler: service.NewPolicyCompiler(service.WithoutSubdivisions())}
+}
+
+// echoCountry reports the country of the stored result
+var echoCountry = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	result, ok := ResultFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte(result.Country))
+})
+
+func TestMiddleware(t *testing.T) {
+	mw, err := Middleware(newStubVerifier(), usOnly, WithTrustedProxies("10.0.0.0/8"))
+	require.NoError(t, err)
+	h := mw(echoCountry)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"allowed", "8.8.8.8:1234", "", http.StatusOK, "US"},
+		{"denied", "77.88.8.8:1234", "", http.StatusForbidden, `{"error":"Access denied"}`},
+		{"lookup failure", "192.0.2.1:1234", "", http.StatusInternalServerError, `{"error":"Failed to lookup IP address"}`},
+		{"trusted proxy", "10.0.0.2:1234", "8.8.8.8", http.StatusOK, "US"},
+		{"untrusted proxy", "203.0.113.1:1234", "8.8.8.8", http.StatusInternalServerError, `{"error":"Failed to lookup IP address"}`},
+		{"invalid forwarding header", "10.0.0.2:1234", "garbage", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				if tt.expectedStatus == http.StatusOK {
+					assert.Equal(t, tt.expectedBody, w.Body.String())
+				} else {
+					assert.JSONEq(t, tt.expectedBody, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware_ClientIPHeader(t *testing.T) {
+	mw, err := Middleware(newStubVerifier(), usOnly, WithTrustedProxies("10.0.0.0/8"), WithClientIPHeader("X-Real-IP"))
+	require.NoError(t, err)
+	h := mw(echoCountry)
+
+	// The proxy sets X-Real-IP; an X-Forwarded-For sent by the client is
+	// passed through and must not be used
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Set("X-Forwarded-For", "77.88.8.8")
+	req.Header.Set("X-Real-IP", "8.8.8.8")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "US", w.Body.String())
+}
+
+func TestMiddleware_CustomHandlers(t *testing.T) {
+	var denied *Result
+	var failed error
+	mw, err := Middleware(newStubVerifier(), usOnly,
+		WithClientIP(func(r *http.Request) (netip.Addr, error) {
+			if r.Header.Get("X-Client-IP") == "" {
+				return netip.Addr{}, errors.New("missing X-Client-IP")
+			}
+			return netip.ParseAddr(r.Header.Get("X-Client-IP"))
+		}),
+		WithDenyHandler(func(w http.ResponseWriter, r *http.Request, result *Result) {
+			denied = result
+			http.Redirect(w, r, "/blocked", http.StatusFound)
+		}),
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			failed = err
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+	)
+	require.NoError(t, err)
+	h := mw(echoCountry)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Client-IP", "77.88.8.8")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	require.NotNil(t, denied)
+	assert.Equal(t, ReasonNotInAllowlist, denied.Reason)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.True(t, apperrors.IsValidationError(failed))
+}
+
+func TestMiddleware_InvalidOptions(t *testing.T) {
+	_, err := Middleware(newStubVerifier(), Policy{})
+	assert.Error(t, err)
+
+	_, err = Middleware(newStubVerifier(), Policy{AllowedCountries: []string{"group:unknown"}})
+	assert.Error(t, err)
+
+	// The stub verifier has no City database
+	_, err = Middleware(newStubVerifier(), Policy{AllowedCountries: []string{"US-CA"}})
+	assert.Error(t, err)
+
+	_, err = Middleware(newStubVerifier(), usOnly, WithTrustedProxies("not-a-cidr"))
+	assert.Error(t, err)
+
+	_, err = Middleware(newStubVerifier(), usOnly, WithTrustedProxies("10.0.0.0/8"), WithClientIPHeader("X-Client-IP"))
+	assert.Error(t, err)
+
+	_, err = GinMiddleware(newStubVerifier(), Policy{})
+	assert.Error(t, err)
+}
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mw, err := GinMiddleware(newStubVerifier(), usOnly)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(mw)
+	router.GET("/", func(c *gin.Context) {
+		result, ok := ResultFromContext(c.Request.Context())
+		require.True(t, ok)
+		c.String(http.StatusOK, result.Country)
+	})
+
+	tests := []struct {
+		remoteAddr     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"8.8.8.8:1234", http.StatusOK, "US"},
+		{"77.88.8.8:1234", http.StatusForbidden, `{"error":"Access denied"}`},
+		{"192.0.2.1:1234", http.StatusInternalServerError, `{"error":"Failed to lookup IP address"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Package geoblock embeds the IP verifier in a Go process. It opens the
+// GeoIP databases directly and provides net/http and Gin middleware that
+// block requests whose client IP fails a policy, without a network hop to
+// the ip-verifier service.
+package geoblock
+
+import (
+	"context"
+	"errors"
+	"github.com/KWilliams-dev/ip-verifier/internal/domain"
+	"github.com/KWilliams-dev/ip-verifier/internal/repo"
+	"github.com/KWilliams-dev/ip-verifier/internal/service"
+	"time"
+)
+
+// Policy, Result and their value types are the verifier's own types; see the
+// ip-verifier README for the meaning of each policy field and reason.
+type (
+	Policy        = domain.Policy
+	Result        = domain.VerifyResult
+	LookupResult  = domain.LookupResult
+	MatchMode     = domain.MatchMode
+	UnknownAction = domain.UnknownAction
+	AnonymityType = domain.AnonymityType
+	Reason        = domain.Reason
+	RangeType     = domain.RangeType
+)
+
+const (
+	MatchPhysical   = domain.MatchPhysical
+	MatchRegistered = domain.MatchRegistered
+	MatchAny        = domain.MatchAny
+	MatchAll        = domain.MatchAll
+
+	UnknownActionAllow = domain.UnknownActionAllow
+	UnknownActionDeny  = domain.UnknownActionDeny
+	UnknownActionError = domain.UnknownActionError
+
+	AnonymityVPN         = domain.AnonymityVPN
+	AnonymityTor         = domain.AnonymityTor
+	AnonymityHosting     = domain.AnonymityHosting
+	AnonymityPublicProxy = domain.AnonymityPublicProxy
+
+	ReasonCountryAllowed  = domain.ReasonCountryAllowed
+	ReasonCountryDenied   = domain.ReasonCountryDenied
+	ReasonNotInAllowlist  = domain.ReasonNotInAllowlist
+	ReasonUnknownLocation = domain.ReasonUnknownLocation
+	ReasonPrivateRange    = domain.ReasonPrivateRange
+	ReasonASNAllowed      = domain.ReasonASNAllowed
+	ReasonASNDenied       = domain.ReasonASNDenied
+	ReasonCIDRAllowed     = domain.ReasonCIDRAllowed
+	ReasonCIDRDenied      = domain.ReasonCIDRDenied
+	ReasonAnonymousDenied = domain.ReasonAnonymousDenied
+)
+
+// Config lists the data files to open. Only CountryDBPath is required.
+type Config struct {
+	CountryDBPath     string // GeoLite2-Country database
+	CityDBPath        string // Optional GeoLite2-City database for subdivision rules and city data
+	ASNDBPath         string // Optional GeoLite2-ASN database for ASN data and rules
+	AnonymousIPDBPath string // Optional GeoIP2-Anonymous-IP database for VPN, hosting and proxy detection
+	TorExitListPath   string // Optional file of Tor exit node addresses
+}
+
+// source is a data file that can be reloaded in place
+type source interface {
+	Reload() error
+	Watch(ctx context.Context, interval time.Duration)
+}
+
+// Verifier verifies IPs against policies using local GeoIP databases. It is
+// safe for concurrent use.
+type Verifier struct {
+	service   service.IPVerifierService
+	compiler  *service.PolicyCompiler
+	sources   []source
+	databases []*repo.Database
+}
+
+// Open opens the configured databases. Close releases them.
+func Open(cfg Config) (*Verifier, error) {
+	if cfg.CountryDBPath == "" {
+		return nil, errors.New("geoblock: CountryDBPath is required")
+	}
+
+	v := &Verifier{}
+	db, err := v.openDatabase(cfg.CountryDBPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var repoOpts []repo.Option
+	optional := []struct {
+		path   string
+		option func(*repo.Database) repo.Option
+	}{
+		{cfg.CityDBPath, repo.WithCityDatabase},
+		{cfg.ASNDBPath, repo.WithASNDatabase},
+		{cfg.AnonymousIPDBPath, repo.WithAnonymousIPDatabase},
+	}
+	for _, o := range optional {
+		if o.path == "" {
+			continue
+		}
+		optionalDB, err := v.openDatabase(o.path)
+		if err != nil {
+			v.Close()
+			return nil, err
+		}
+		repoOpts = append(repoOpts, o.option(optionalDB))
+	}
+
+	if cfg.TorExitListPath != "" {
+		torList, err := repo.OpenTorExitList(cfg.TorExitListPath)
+		if err != nil {
+			v.Close()
+			return nil, err
+		}
+		v.sources = append(v.sources, torList)
+		repoOpts = append(repoOpts, repo.WithTorExitList(torList))
+	}
+
+	var compilerOpts []service.CompilerOption
+	if cfg.CityDBPath == "" {
+		compilerOpts = append(compilerOpts, service.WithoutSubdivisions())
+	}
+	v.compiler = service.NewPolicyCompiler(compilerOpts...)
+	v.service = service.NewIPVerifierService(repo.NewIPVerifierRepo(db, repoOpts...),
+		service.WithPolicyCompiler(v.compiler))
+	return v, nil
+}
+
+func (v *Verifier) openDatabase(path string) (*repo.Database, error) {
+	db, err := repo.OpenDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	v.databases = append(v.databases, db)
+	v.sources = append(v.sources, db)
+	return db, nil
+}
+
+// Verify checks ip against policy
+func (v *Verifier) Verify(ctx context.Context, ip string, policy Policy) (*Result, error) {
+	return v.service.VerifyIP(ctx, ip, policy)
+}
+
+// Lookup returns the data known about ip without applying a policy
+func (v *Verifier) Lookup(ctx context.Context, ip string) (*LookupResult, error) {
+	return v.service.LookupIP(ctx, ip)
+}
+
+// Reload reopens every data file. Files that fail to load keep their
+// current contents; the first error is returned.
+func (v *Verifier) Reload() error {
+	var first error
+	for _, s := range v.sources {
+		if err := s.Reload(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Watch polls the data files every interval and reloads those that changed.
+// It blocks until ctx is cancelled.
+func (v *Verifier) Watch(ctx context.Context, interval time.Duration) {
+	done := make(chan struct{})
+	for _, s := range v.sources {
+		go func() {
+			s.Watch(ctx, interval)
+			done <- struct{}{}
+		}()
+	}
+	for range v.sources {
+		<-done
+	}
+}
+
+// Close releases the databases. The Verifier must not be used afterwards.
+func (v *Verifier) Close() error {
+	var first error
+	for _, db := range v.databases {
+		if err := db.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ValidatePolicy reports whether policy is usable with the opened databases,
+// returning the same error Verify would. Unlike the ValidatePolicy function it
+// rejects subdivision rules when no City database is configured.
+func (v *Verifier) ValidatePolicy(policy Policy) error {
+	return v.compiler.Validate(policy)
+}
+
+// ValidatePolicy reports whether policy is well-formed, whichever databases
+// it will be verified with
+func ValidatePolicy(policy Policy) error {
+	return service.ValidatePolicy(policy)
+}
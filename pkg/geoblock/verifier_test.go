@@ -0,0 +1,51 @@
+package geoblock
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_RequiresCountryDatabase(t *testing.T) {
+	_, err := Open(Config{})
+	assert.Error(t, err)
+}
+
+func TestOpen_MissingOptionalDatabase(t *testing.T) {
+	if _, err := os.Stat("../../data/GeoLite2-Country.mmdb"); err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+	}
+
+	_, err := Open(Config{
+		CountryDBPath: "../../data/GeoLite2-Country.mmdb",
+		ASNDBPath:     "../../data/missing.mmdb",
+	})
+	assert.Error(t, err)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	// This test requires the actual GeoLite2-Country.mmdb file
+	v, err := Open(Config{CountryDBPath: "../../data/GeoLite2-Country.mmdb"})
+	if err != nil {
+		t.Skip("Skipping test: GeoLite2-Country.mmdb not found")
+	}
+	defer v.Close()
+
+	ctx := context.Background()
+
+	result, err := v.Verify(ctx, "8.8.8.8", Policy{AllowedCountries: []string{"US"}})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, "US", result.Country)
+	assert.Equal(t, ReasonCountryAllowed, result.Reason)
+
+	lookup, err := v.Lookup(ctx, "8.8.8.8")
+	require.NoError(t, err)
+	require.NotNil(t, lookup.Location)
+	assert.Equal(t, "US", lookup.Location.Country)
+
+	assert.NoError(t, v.Reload())
+}
@@ -1,6 +1,6 @@
 #!/bin/bash
 
-# Script to create MaxMind credentials secret from .env file
+# Script to create the MaxMind credentials and policy admin token secrets from .env file
 # Usage: ./scripts/create-secret.sh
 
 set -e
@@ -49,10 +49,20 @@ kubectl create secret generic maxmind-credentials \
   --from-literal=GEOIPUPDATE_LICENSE_KEY="${GEOIPUPDATE_LICENSE_KEY}" \
   --from-literal=GEOIPUPDATE_EDITION_IDS=GeoLite2-Country
 
+# Create the policy admin token secret, generating a token unless .env sets one
+if [ -z "$ADMIN_TOKEN" ]; then
+    ADMIN_TOKEN=$(openssl rand -hex 32)
+    echo -e "${YELLOW}Generated ADMIN_TOKEN (add it to .env to keep it): ${ADMIN_TOKEN}${NC}"
+fi
+kubectl delete secret ip-verifier-admin -n ip-verifier 2>/dev/null || true
+kubectl create secret generic ip-verifier-admin \
+  --namespace=ip-verifier \
+  --from-literal=ADMIN_TOKEN="${ADMIN_TOKEN}"
+
 # Verify
-echo -e "\n${GREEN}✓ Secret created successfully${NC}"
-echo -e "\nVerifying secret..."
-kubectl get secret maxmind-credentials -n ip-verifier
+echo -e "\n${GREEN}✓ Secrets created successfully${NC}"
+echo -e "\nVerifying secrets..."
+kubectl get secret maxmind-credentials ip-verifier-admin -n ip-verifier
 
 echo -e "\n${GREEN}✓ Done!${NC}"
 echo -e "\nNext steps:"
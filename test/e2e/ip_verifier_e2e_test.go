@@ -19,7 +19,8 @@ const baseURL = "http://localhost:8080"
 
 type VerifyRequest struct {
 	IP               string   `json:"ip"`
-	AllowedCountries []string `json:"allowed_countries"`
+	AllowedCountries []string `json:"allowed_countries,omitempty"`
+	DeniedCountries  []string `json:"denied_countries,omitempty"`
 }
 
 type VerifyResponse struct {
@@ -95,6 +96,22 @@ func TestVerifyIP_ValidIPNotInAllowedCountries(t *testing.T) {
 	assert.False(t, verifyResp.Allowed)
 }
 
+func TestVerifyIP_DeniedCountries(t *testing.T) {
+	req := VerifyRequest{
+		IP:              "8.8.8.8",
+		DeniedCountries: []string{"US"},
+	}
+
+	resp := makeVerifyRequest(t, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var verifyResp VerifyResponse
+	decodeJSON(t, resp, &verifyResp)
+
+	assert.Equal(t, "US", verifyResp.Country)
+	assert.False(t, verifyResp.Allowed)
+}
+
 func TestVerifyIP_InvalidIPAddress(t *testing.T) {
 	req := VerifyRequest{
 		IP:               "invalid-ip",